@@ -4,12 +4,31 @@ cd "$(dirname "$0")"
 
 build_wasm() {
     GOOS="js" GOARCH="wasm" go build -o ./site/js/main.wasm ./wasm
+    write_sw_version
+}
+
+# Versions the service worker cache so every build busts it.
+# Deploys pass SW_VERSION (the commit), local builds hash the site's contents.
+write_sw_version() {
+    version="$SW_VERSION"
+    if [ -z "$version" ]; then
+        hash_cmd="sha256sum"
+        command -v sha256sum > /dev/null || hash_cmd="shasum -a 256"
+        version=$(find ./site -type f ! -name sw-version.js -print0 | sort -z | xargs -0 $hash_cmd | $hash_cmd | cut -c1-12)
+    fi
+    printf '// Written by build.sh, not checked in\nself.CACHE_VERSION = "%s";\n' "$version" > ./site/sw-version.js
 }
 
 build_server() {
     go build -o ./main ./server
 }
 
+# One binary serving both the site and the api
+build_embedded() {
+    build_wasm
+    go build -tags embed_site -o ./main ./server
+}
+
 # Parse arguments
 if [ $# -eq 0 ]; then
     # No arguments, build both
@@ -24,12 +43,16 @@ else
             server)
                 build_server
                 ;;
+            embedded)
+                build_embedded
+                ;;
             *)
                 echo "Unknown argument: $arg"
-                echo "Usage: $0 [wasm] [server]"
+                echo "Usage: $0 [wasm] [server] [embedded]"
                 echo "  No arguments: build both"
                 echo "  wasm: build only WASM"
                 echo "  server: build only server"
+                echo "  embedded: build the server with the site and WASM inside it"
                 exit 1
                 ;;
         esac
@@ -0,0 +1,197 @@
+// Plays the daily libble in a terminal with the same rules as the site
+//
+//	go run ./cmd/libble-tui -id <libble id> -token <token>
+//	go run ./cmd/libble-tui -pair <code from Transfer on the site>
+//	go run ./cmd/libble-tui -file saves/<libble id>
+package main
+
+import (
+	"bufio"
+	"flag"
+	"fmt"
+	"os"
+	"slices"
+	"strconv"
+
+	. "libble/shared"
+
+	"github.com/charmbracelet/log"
+)
+
+var (
+	logg = log.NewWithOptions(os.Stderr, log.Options{
+		ReportTimestamp: false,
+	})
+)
+
+const defaultServer = "https://libble.onrender.com"
+
+func main() {
+	server := flag.String("server", defaultServer, "libble server to load the save from")
+	id := flag.String("id", os.Getenv("LIBBLE_ID"), "libble id of the player, defaults to $LIBBLE_ID")
+	token := flag.String("token", os.Getenv("LIBBLE_TOKEN"), "token for the player's save, defaults to $LIBBLE_TOKEN")
+	pairCode := flag.String("pair", "", "pairing code from Transfer on the site, used instead of -id and -token")
+	file := flag.String("file", "", "play from a local save file instead of the server")
+	modeName := flag.String("mode", "classic", "game mode: classic, multi_quote or author")
+	day := flag.String("day", "", "play a past day from the archive (YYYY-MM-DD)")
+	cooldown := flag.Int("cooldown", -1, fmt.Sprintf("days before a book can come back, 0 turns it off (default %d)", DefaultBookCooldownDays))
+	flag.Parse()
+
+	mode := GameMode(*modeName)
+	if mode == "classic" {
+		mode = ClassicMode
+	}
+	if !slices.Contains(GameModes, mode) {
+		logg.Fatalf("Unknown game mode %s", *modeName)
+	}
+
+	var saves store
+	if *file != "" {
+		saves = &fileStore{path: *file}
+	} else if *pairCode != "" {
+		paired, err := redeemPairCode(*server, *pairCode)
+		if err != nil {
+			logg.Fatal(err)
+		}
+		fmt.Printf("Paired! Next time use -id %d -token %s\n", paired.id, paired.token)
+		saves = paired
+	} else if *id != "" {
+		playerId, err := strconv.ParseUint(*id, 10, 64)
+		if err != nil {
+			logg.Fatalf("Invalid libble id %s", *id)
+		}
+		saves = serverStore{origin: *server, id: DBID(playerId), token: *token}
+	} else {
+		flag.Usage()
+		os.Exit(2)
+	}
+
+	data, err := saves.Load()
+	if err != nil {
+		logg.Fatal(err)
+	}
+	data.Migrate()
+
+	if *cooldown >= 0 {
+		if err := data.Player.SetBookCooldown(*cooldown); err != nil {
+			logg.Fatal(err)
+		}
+		fmt.Printf("Books now sit out for %d days after they come up\n", data.Player.BookCooldown())
+	}
+
+	if *day == "" {
+		*day = data.Player.Today()
+	} else if _, err := DayNumber(*day); err != nil || *day > data.Player.Today() {
+		logg.Fatalf("Can't play %s", *day)
+	}
+	game, err := data.StartDailyGame(*day, mode)
+	if err != nil {
+		logg.Fatal(err)
+	}
+	// Saved right away so the quote picked for the day sticks
+	save(saves, data)
+
+	play(&data, game, saves, bufio.NewScanner(os.Stdin))
+}
+
+func play(data *SaveData, game *Game, saves store, scanner *bufio.Scanner) {
+	controller := NewController(data, game)
+
+	fmt.Printf("📖 Libble #%d (%s) %s\n", PuzzleNumber(game.Day), game.Mode, game.Day)
+	printQuote(game)
+	printGuesses(data, game)
+	if status, ok := controller.Status(); !ok {
+		printResult(status)
+		printStats(data.Stats(game.Mode))
+		return
+	}
+	fmt.Println("Type a guess, end it with ? to see suggestions, or /help for commands")
+
+	// Numbered picks from the last list of suggestions or choices
+	var options []option
+
+	for {
+		fmt.Print("> ")
+		if !scanner.Scan() {
+			fmt.Println()
+			return
+		}
+		input := scanner.Text()
+
+		var result Result
+		switch command(input) {
+		case "":
+			printQuote(game)
+			continue
+		case "/help":
+			printHelp()
+			continue
+		case "/quit", "/exit":
+			return
+		case "/stats":
+			printStats(data.Stats(game.Mode))
+			continue
+		case "/hint":
+			result = controller.UseHint(UnredactHint)
+		case "/skip":
+			if game.Started() {
+				fmt.Println("⚠️ You can only skip before guessing or using a hint")
+				continue
+			}
+			result, err := controller.Skip(newRng())
+			if err != nil {
+				logg.Error(err)
+			}
+			printResult(result)
+			save(saves, *data)
+			printQuote(game)
+			continue
+		default:
+			if pick, err := strconv.Atoi(input); err == nil && pick > 0 && pick <= len(options) {
+				result = options[pick-1].pick()
+			} else if query, found := suggestionQuery(input); found {
+				options = suggestions(controller, query)
+				printOptions(options)
+				continue
+			} else if game.Mode == AuthorMode {
+				result = controller.GuessAuthor(input)
+			} else {
+				result = controller.GuessTitle(input)
+			}
+		}
+
+		printResult(result)
+		options = nil
+		if len(result.Choices) > 0 {
+			options = choices(controller, result.Choices)
+			printOptions(options)
+		} else if result.Has(RejectedEvent) && !game.Completed() {
+			// Maybe it was just misspelled
+			options = suggestions(controller, input)
+			printOptions(options)
+		}
+		if !result.Changed() {
+			continue
+		}
+
+		save(saves, *data)
+		if result.Has(QuoteRevealedEvent) || result.Has(HintUsedEvent) {
+			printQuote(game)
+		}
+		if result.Has(GuessedEvent) {
+			printGuesses(data, game)
+		}
+		if result.Has(CompletedEvent) {
+			fmt.Println()
+			fmt.Println(game.ShareText(*data))
+			printStats(data.Stats(game.Mode))
+			return
+		}
+	}
+}
+
+func save(saves store, data SaveData) {
+	if err := saves.Save(data); err != nil {
+		logg.Errorf("Failed saving progress: %v", err)
+	}
+}
@@ -0,0 +1,230 @@
+package main
+
+import (
+	"cmp"
+	"fmt"
+	"math/rand"
+	"slices"
+	"strings"
+	"time"
+
+	. "libble/shared"
+
+	"github.com/sahilm/fuzzy"
+)
+
+const (
+	lineWidth      = 72
+	maxSuggestions = 8
+)
+
+func command(input string) string {
+	input = strings.TrimSpace(input)
+	if strings.HasPrefix(input, "/") {
+		return strings.ToLower(input)
+	}
+	if input == "" {
+		return ""
+	}
+	return "guess"
+}
+
+func suggestionQuery(input string) (string, bool) {
+	query, found := strings.CutSuffix(strings.TrimSpace(input), "?")
+	return strings.TrimSpace(query), found
+}
+
+func newRng() *rand.Rand {
+	return rand.New(rand.NewSource(time.Now().UnixNano()))
+}
+
+type option struct {
+	label string
+	pick  func() Result
+}
+
+// Closest books (by title or author) or authors to the query, the same search the site's autocomplete uses
+func suggestions(controller *Controller, query string) []option {
+	options := make([]option, 0, maxSuggestions)
+	if controller.Game.Mode == AuthorMode {
+		authors := controller.Data.Authors()
+		for _, match := range fuzzy.FindFrom(query, authors) {
+			author := authors[match.Index]
+			options = append(options, option{
+				label: author.Name,
+				pick:  func() Result { return controller.GuessAuthor(author.Name) },
+			})
+		}
+	} else {
+		books := controller.Data.SortedBooks()
+		for _, match := range Search(query, books, BookAuthors(books)) {
+			book := books[match.Index]
+			options = append(options, option{
+				label: fmt.Sprintf("%s by %s", book.Book.CleanTitle(), book.Book.Author),
+				pick:  func() Result { return controller.GuessBook(book.Id) },
+			})
+		}
+	}
+	return options[:min(len(options), maxSuggestions)]
+}
+
+func choices(controller *Controller, bookIds []BookId) []option {
+	label := func(book Book) string {
+		return fmt.Sprintf("%s by %s", book.CleanTitle(), book.Author)
+	}
+	labelCounts := map[string]int{}
+	for _, bookId := range bookIds {
+		labelCounts[label(controller.Data.Books[bookId].Book)]++
+	}
+
+	options := make([]option, 0, len(bookIds))
+	for _, bookId := range bookIds {
+		book := controller.Data.Books[bookId].Book
+		text := label(book)
+		if labelCounts[text] > 1 { // separate editions of the same book
+			text += fmt.Sprintf(" (edition %s)", book.BookGRID)
+		}
+		options = append(options, option{
+			label: text,
+			pick:  func() Result { return controller.GuessBook(bookId) },
+		})
+	}
+	return options
+}
+
+func printOptions(options []option) {
+	if len(options) == 0 {
+		return
+	}
+	fmt.Println("Pick one by number:")
+	for i, option := range options {
+		fmt.Printf("  %d. %s\n", i+1, option.label)
+	}
+}
+
+func printResult(result Result) {
+	if result.Message == "" {
+		return
+	}
+	emoji := ""
+	switch result.Status {
+	case ErrorStatus:
+		emoji = "❌ "
+	case SuccessStatus:
+		emoji = "🎉 "
+	case WarnStatus:
+		emoji = "⚠️ "
+	}
+	fmt.Println(emoji + result.Message)
+}
+
+func printQuote(game *Game) {
+	fmt.Println()
+	for _, text := range game.QuoteTexts() {
+		for _, line := range wrap("“"+text+"”", lineWidth) {
+			fmt.Println("  " + line)
+		}
+		fmt.Println()
+	}
+}
+
+func printGuesses(data *SaveData, game *Game) {
+	if game.Mode == AuthorMode {
+		authors := data.Authors()
+		for _, guessGRID := range game.AuthorGuesses {
+			index := slices.IndexFunc(authors, func(a Author) bool { return a.GRID == guessGRID })
+			if index < 0 {
+				continue
+			}
+			mark := "❌"
+			if guessGRID == game.Book.Book.AuthorGRID {
+				mark = "✅"
+			}
+			fmt.Printf("%s %s\n", mark, authors[index].Name)
+		}
+		return
+	}
+
+	for _, guessId := range game.Guesses {
+		guess, found := data.Books[guessId]
+		if !found {
+			continue
+		}
+		if guessId == game.BookId {
+			fmt.Printf("✅ %s\n", guess.Book.CleanTitle())
+			continue
+		}
+		clues := make([]string, 0, 5)
+		for _, clue := range CompareBooks(guess, game.Book) {
+			if clue.Result != ClueUnknown {
+				clues = append(clues, clue.String())
+			}
+		}
+		fmt.Printf("❌ %s\n   %s\n", guess.Book.CleanTitle(), strings.Join(clues, " · "))
+	}
+}
+
+func printStats(stats Stats) {
+	fmt.Printf("\n%s stats\n", stats.Mode)
+	fmt.Printf("Played %d · Win %% %.0f · Current streak %d · Max streak %d\n",
+		stats.Played, stats.WinPercent, stats.CurrentStreak, stats.MaxStreak)
+
+	mostWins := max(slices.Max(stats.GuessDistribution[:]), 1)
+	for i, wins := range stats.GuessDistribution {
+		bar := strings.Repeat("█", max(wins*20/mostWins, 1))
+		fmt.Printf("  %d %s %d\n", i+1, bar, wins)
+	}
+	fmt.Printf("Average attempts: %.1f\n", stats.AverageAttempts)
+	if stats.ArchivePlayed > 0 {
+		fmt.Printf("Archive games won: %d/%d\n", stats.ArchiveWon, stats.ArchivePlayed)
+	}
+
+	authors := make([]string, 0, len(stats.ByAuthor))
+	for author := range stats.ByAuthor {
+		authors = append(authors, author)
+	}
+	slices.SortFunc(authors, func(a string, b string) int {
+		return cmp.Or(
+			cmp.Compare(stats.ByAuthor[b].Played, stats.ByAuthor[a].Played),
+			strings.Compare(a, b),
+		)
+	})
+	if len(authors) > 0 {
+		fmt.Println("By author:")
+	}
+	for _, author := range authors[:min(len(authors), 5)] {
+		accuracy := stats.ByAuthor[author]
+		fmt.Printf("  %s: %d/%d (%.0f%%)\n", author, accuracy.Won, accuracy.Played, accuracy.Percent())
+	}
+}
+
+func printHelp() {
+	fmt.Println(`Type a title (or an author in author mode) to guess it.
+  title?   list the closest matches without guessing
+  1-8      pick from the last list
+  /hint    reveal the redacted words
+  /skip    swap the quote before you start guessing
+  /stats   show your stats
+  /quit    stop playing, progress is already saved
+  (empty)  show the quote again`)
+}
+
+// Splits text into lines no longer than width, breaking between words
+func wrap(text string, width int) []string {
+	lines := make([]string, 0, 4)
+	line := ""
+	for _, word := range strings.Fields(text) {
+		if line != "" && len([]rune(line))+1+len([]rune(word)) > width {
+			lines = append(lines, line)
+			line = ""
+		}
+		if line != "" {
+			line += " "
+		}
+		line += word
+	}
+	if line != "" {
+		lines = append(lines, line)
+	}
+	return lines
+}
@@ -0,0 +1,183 @@
+package main
+
+import (
+	"bytes"
+	"compress/gzip"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"strconv"
+	"strings"
+
+	. "libble/shared"
+)
+
+// Where the save comes from and where progress goes back to
+type store interface {
+	Load() (SaveData, error)
+	Save(data SaveData) error
+}
+
+// A save file like the ones the server keeps in saves/, gzipped or plain json
+type fileStore struct {
+	path       string
+	compressed bool
+}
+
+func (f *fileStore) Load() (SaveData, error) {
+	var data SaveData
+	saveBytes, err := os.ReadFile(f.path)
+	if err != nil {
+		return data, fmt.Errorf("Failed reading save file: %v", err)
+	}
+
+	// gzip magic number
+	f.compressed = bytes.HasPrefix(saveBytes, []byte{0x1f, 0x8b})
+	if f.compressed {
+		decompresser, err := gzip.NewReader(bytes.NewReader(saveBytes))
+		if err != nil {
+			return data, fmt.Errorf("Failed creating gzip reader: %v", err)
+		}
+		defer decompresser.Close()
+		if saveBytes, err = io.ReadAll(decompresser); err != nil {
+			return data, fmt.Errorf("Failed decompressing save file: %v", err)
+		}
+	}
+
+	if err := json.Unmarshal(saveBytes, &data); err != nil {
+		return data, fmt.Errorf("Failed decoding save data: %v", err)
+	}
+	return data, nil
+}
+
+func (f *fileStore) Save(data SaveData) error {
+	saveBytes, err := json.Marshal(data)
+	if err != nil {
+		return fmt.Errorf("Failed marshalling save data: %v", err)
+	}
+
+	if f.compressed {
+		var compressedBuffer bytes.Buffer
+		compresser := gzip.NewWriter(&compressedBuffer)
+		if _, err := compresser.Write(saveBytes); err != nil {
+			return err
+		}
+		if err := compresser.Close(); err != nil {
+			return err
+		}
+		saveBytes = compressedBuffer.Bytes()
+	}
+
+	if err := os.WriteFile(f.path, saveBytes, 0644); err != nil {
+		return fmt.Errorf("Failed writing save file: %v", err)
+	}
+	return nil
+}
+
+// A save kept by the server, only the player is sent back like the site does
+type serverStore struct {
+	origin string
+	id     DBID
+	token  string // Needed to load the save
+}
+
+// Trades a pairing code for the player's id and token
+func redeemPairCode(origin string, code string) (serverStore, error) {
+	store := serverStore{origin: origin}
+	redeemUrl, err := url.JoinPath(origin, "redeem", strings.ToUpper(strings.TrimSpace(code)))
+	if err != nil {
+		return store, err
+	}
+
+	res, err := http.Post(redeemUrl, "application/json", nil)
+	if err != nil {
+		return store, fmt.Errorf("Failed redeeming pairing code: %v", err)
+	}
+	defer res.Body.Close()
+	var redeemed struct {
+		ID    DBID   `json:"libble_id"`
+		Token string `json:"token"`
+	}
+	if err := readResponse(res, &redeemed); err != nil {
+		return store, fmt.Errorf("Failed redeeming pairing code: %v", err)
+	}
+	store.id = redeemed.ID
+	store.token = redeemed.Token
+	return store, nil
+}
+
+func (s serverStore) url(parts ...string) (string, error) {
+	return url.JoinPath(s.origin, append(parts, strconv.FormatUint(uint64(s.id), 10))...)
+}
+
+func (s serverStore) Load() (SaveData, error) {
+	var data SaveData
+	saveUrl, err := s.url("save")
+	if err != nil {
+		return data, err
+	}
+
+	req, err := http.NewRequest(http.MethodGet, saveUrl, nil)
+	if err != nil {
+		return data, err
+	}
+	req.Header.Set("Authorization", "Bearer "+s.token)
+	res, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return data, fmt.Errorf("Failed fetching save: %v", err)
+	}
+	defer res.Body.Close()
+	if err := readResponse(res, &data); err != nil {
+		return data, fmt.Errorf("Failed fetching save: %v", err)
+	}
+	return data, nil
+}
+
+func (s serverStore) Save(data SaveData) error {
+	playerUrl, err := s.url("player")
+	if err != nil {
+		return err
+	}
+	playerBytes, err := json.Marshal(data.Player)
+	if err != nil {
+		return fmt.Errorf("Failed marshalling player: %v", err)
+	}
+
+	req, err := http.NewRequest(http.MethodPut, playerUrl, bytes.NewReader(playerBytes))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("Authorization", "Bearer "+s.token)
+	res, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return fmt.Errorf("Failed syncing player: %v", err)
+	}
+	defer res.Body.Close()
+	var body map[string]any
+	if err := readResponse(res, &body); err != nil {
+		return fmt.Errorf("Failed syncing player: %v", err)
+	}
+	return nil
+}
+
+// Decodes the json body, turning the server's {"error": ...} responses into errors
+func readResponse(res *http.Response, data any) error {
+	body, err := io.ReadAll(res.Body)
+	if err != nil {
+		return err
+	}
+	if res.StatusCode != http.StatusOK {
+		var errRes struct {
+			Error string `json:"error"`
+		}
+		if json.Unmarshal(body, &errRes) == nil && errRes.Error != "" {
+			return fmt.Errorf("%s: %s", res.Status, errRes.Error)
+		}
+		return fmt.Errorf("%s", res.Status)
+	}
+	return json.Unmarshal(body, data)
+}
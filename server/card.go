@@ -0,0 +1,241 @@
+package main
+
+import (
+	"bytes"
+	"fmt"
+	"html/template"
+	"image"
+	"image/color"
+	"image/draw"
+	"image/png"
+	"net/http"
+	"slices"
+	"strconv"
+
+	. "libble/shared"
+
+	"github.com/gin-gonic/gin"
+)
+
+// Open Graph's recommended image size
+const (
+	cardWidth  = 1200
+	cardHeight = 630
+	cardMargin = 60
+
+	maxCardQuoteLines = 6
+)
+
+var (
+	cardBackground = color.RGBA{0x12, 0x12, 0x12, 0xff}
+	cardAccent     = color.RGBA{0x64, 0xff, 0xda, 0xff}
+	cardText       = color.RGBA{0xf5, 0xf5, 0xf5, 0xff}
+	cardMuted      = color.RGBA{0xaa, 0xaa, 0xaa, 0xff}
+	cardGreen      = color.RGBA{0x00, 0xe6, 0x76, 0xff}
+	cardYellow     = color.RGBA{0xff, 0xb3, 0x00, 0xff}
+	cardRed        = color.RGBA{0xef, 0x53, 0x50, 0xff}
+	cardGrey       = color.RGBA{0x3d, 0x3d, 0x3d, 0xff}
+)
+
+func clueColor(result ClueResult) color.Color {
+	switch result {
+	case ClueMatch:
+		return cardGreen
+	case ClueHigher, ClueLower:
+		return cardYellow
+	}
+	return cardGrey
+}
+
+func cardTitle(game Game) string {
+	score := "X"
+	if game.Won() {
+		score = strconv.Itoa(game.Attempts())
+	}
+	title := fmt.Sprintf("Libble #%d %s/%d", PuzzleNumber(game.Day), score, MaxGuesses)
+	if game.Mode != ClassicMode {
+		title += " " + game.Mode.String()
+	}
+	return title
+}
+
+func renderCard(data SaveData, game Game, streak int, hideQuote bool) *image.RGBA {
+	card := image.NewRGBA(image.Rect(0, 0, cardWidth, cardHeight))
+	fill := func(rect image.Rectangle, c color.Color) {
+		draw.Draw(card, rect, image.NewUniform(c), image.Point{}, draw.Src)
+	}
+	fill(card.Bounds(), cardBackground)
+	fill(image.Rect(0, 0, 12, cardHeight), cardAccent)
+
+	drawText(card, cardMargin, 50, "LIBBLE", 10, cardAccent)
+	drawText(card, cardMargin, 150, cardTitle(game), 4, cardText)
+
+	// Quote on the left half, names stay hidden so the card doesn't spoil it
+	const quoteScale = 3
+	quoteY := 230
+	if hideQuote {
+		drawText(card, cardMargin, quoteY, "Quote hidden", quoteScale, cardMuted)
+	} else {
+		maxChars := (cardWidth/2 - cardMargin) / ((glyphWidth + glyphSpacing) * quoteScale)
+		lines := wrapText(game.Quote.Redacted(game.Book.Book), maxChars)
+		if len(lines) > maxCardQuoteLines {
+			lines = lines[:maxCardQuoteLines]
+			lines[len(lines)-1] += "..."
+		}
+		for i, line := range lines {
+			drawText(card, cardMargin, quoteY+i*(glyphHeight+3)*quoteScale, line, quoteScale, cardText)
+		}
+	}
+
+	// Result grid on the right half, one row per guess
+	const square = 44
+	const gap = 10
+	gridX := cardWidth/2 + cardMargin
+	y := 230
+	drawRow := func(result color.Color, clues []Clue) {
+		fill(image.Rect(gridX, y, gridX+square, y+square), result)
+		x := gridX + square + gap*3
+		for _, clue := range clues {
+			fill(image.Rect(x, y, x+square, y+square), clueColor(clue.Result))
+			x += square + gap
+		}
+		y += square + gap
+	}
+	if game.Mode == AuthorMode {
+		for _, guess := range game.AuthorGuesses {
+			if guess == game.Book.Book.AuthorGRID {
+				drawRow(cardGreen, nil)
+			} else {
+				drawRow(cardRed, nil)
+			}
+		}
+	} else {
+		for _, guess := range game.Guesses {
+			if guess == game.BookId {
+				drawRow(cardGreen, nil)
+			} else if book, found := data.Books[guess]; found {
+				drawRow(cardRed, CompareBooks(book, game.Book))
+			} else {
+				drawRow(cardRed, nil)
+			}
+		}
+	}
+
+	drawText(card, gridX, y+20, fmt.Sprintf("Streak: %d", streak), 4, cardText)
+	if hints := len(game.Hints); hints > 0 {
+		drawText(card, gridX, y+70, fmt.Sprintf("Hints: %d", hints), 3, cardMuted)
+	}
+
+	footer := "libble.you"
+	drawText(card, cardWidth-cardMargin-textWidth(footer, 4), cardHeight-cardMargin-glyphHeight*4, footer, 4, cardMuted)
+	return card
+}
+
+// Writes the error response itself when the game can't be found or isn't over yet
+func finishedGameParam(c *gin.Context) (SaveData, *Game, bool) {
+	userID, ok := userIDParam(c)
+	if !ok {
+		return SaveData{}, nil, false
+	}
+
+	day := c.Param("day")
+	if _, err := DayNumber(day); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return SaveData{}, nil, false
+	}
+	mode := GameMode(c.Query("mode"))
+	if !slices.Contains(GameModes, mode) {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Unknown game mode " + string(mode)})
+		return SaveData{}, nil, false
+	}
+
+	saveData, err := loadUserData(userID)
+	if err != nil {
+		errMsg := fmt.Sprintf("Failed loading user data: %v", err)
+		c.JSON(http.StatusBadRequest, gin.H{"error": errMsg})
+		return saveData, nil, false
+	}
+
+	game := saveData.Player.DailyGame(day, mode)
+	if game == nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "No game was played on " + day})
+		return saveData, nil, false
+	}
+	if err := game.Init(saveData); err != nil || !game.Completed() {
+		c.JSON(http.StatusNotFound, gin.H{"error": "The game on " + day + " isn't finished"})
+		return saveData, nil, false
+	}
+	return saveData, game, true
+}
+
+func cardHandler(c *gin.Context) {
+	saveData, game, ok := finishedGameParam(c)
+	if !ok {
+		return
+	}
+
+	streak := saveData.Stats(game.Mode).CurrentStreak
+	_, hideQuote := c.GetQuery("hide_quote")
+	card := renderCard(saveData, *game, streak, hideQuote)
+
+	var buffer bytes.Buffer
+	if err := png.Encode(&buffer, card); err != nil {
+		errMsg := fmt.Sprintf("Failed encoding card: %v", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": errMsg})
+		return
+	}
+	c.Header("Cache-Control", "public, max-age=3600")
+	c.Data(http.StatusOK, "image/png", buffer.Bytes())
+}
+
+var sharePage = template.Must(template.New("share").Parse(`<!DOCTYPE html>
+<html lang="en">
+	<head>
+		<meta charset="UTF-8">
+		<title>{{.Title}}</title>
+		<meta property="og:type" content="website">
+		<meta property="og:site_name" content="Libble">
+		<meta property="og:title" content="{{.Title}}">
+		<meta property="og:description" content="{{.Description}}">
+		<meta property="og:image" content="{{.Image}}">
+		<meta property="og:image:width" content="{{.Width}}">
+		<meta property="og:image:height" content="{{.Height}}">
+		<meta property="og:url" content="{{.URL}}">
+		<meta name="twitter:card" content="summary_large_image">
+		<meta http-equiv="refresh" content="0; url={{.Site}}">
+	</head>
+	<body>
+		<a href="{{.Site}}">Play Libble</a>
+	</body>
+</html>
+`))
+
+// Unfurls into the result card in chats, then sends people on to the game
+func shareHandler(c *gin.Context) {
+	saveData, game, ok := finishedGameParam(c)
+	if !ok {
+		return
+	}
+
+	scheme := "http"
+	if c.Request.TLS != nil || c.GetHeader("X-Forwarded-Proto") == "https" {
+		scheme = "https"
+	}
+	cardURL := fmt.Sprintf("%s://%s/card/%s/%s?%s",
+		scheme, c.Request.Host, c.Param("id"), c.Param("day"), c.Request.URL.RawQuery)
+
+	c.Status(http.StatusOK)
+	c.Header("Content-Type", "text/html; charset=utf-8")
+	err := sharePage.Execute(c.Writer, gin.H{
+		"Title":       cardTitle(*game),
+		"Description": game.ShareText(saveData),
+		"Image":       cardURL,
+		"Width":       cardWidth,
+		"Height":      cardHeight,
+		"URL":         fmt.Sprintf("%s://%s%s", scheme, c.Request.Host, c.Request.URL.RequestURI()),
+		"Site":        siteOrigin,
+	})
+	if err != nil {
+		logg.Errorf("Failed rendering share page: %v", err)
+	}
+}
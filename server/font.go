@@ -0,0 +1,132 @@
+package main
+
+import (
+	_ "embed"
+	"fmt"
+	"image"
+	"image/color"
+	"image/draw"
+	"strconv"
+	"strings"
+
+	. "libble/shared"
+)
+
+//go:embed font5x7.txt
+var fontData string
+
+const (
+	glyphWidth   = 5
+	glyphHeight  = 7
+	glyphSpacing = 1 // Empty columns between glyphs
+)
+
+// One byte per row, the lowest glyphWidth bits are pixels with the leftmost first
+type glyph [glyphHeight]uint8
+
+var font = parseFont(fontData)
+
+// Swaps typographic characters common in quotes for ones the font has
+var fontReplacer = strings.NewReplacer(
+	"‘", "'", "’", "'", "“", "\"", "”", "\"",
+	"—", "-", "–", "-", "…", "...", "\u00a0", " ",
+)
+
+func parseFont(data string) map[rune]glyph {
+	glyphs := make(map[rune]glyph)
+	lines := strings.Split(data, "\n")
+	for i := 0; i < len(lines); i++ {
+		line := strings.TrimSpace(lines[i])
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+
+		codePoint, err := strconv.ParseUint(line, 16, 32)
+		if err != nil || i+glyphHeight >= len(lines) {
+			panic(fmt.Sprintf("Malformed glyph header '%s' in embedded font", line))
+		}
+		var g glyph
+		for row := range glyphHeight {
+			i++
+			for col, pixel := range lines[i] {
+				if pixel == '#' {
+					g[row] |= 1 << (glyphWidth - 1 - col)
+				}
+			}
+		}
+		glyphs[rune(codePoint)] = g
+	}
+
+	// Redacted names are drawn as solid blocks
+	var block glyph
+	for row := range block {
+		block[row] = 1<<glyphWidth - 1
+	}
+	glyphs[RedactedRune] = block
+	return glyphs
+}
+
+func glyphFor(r rune) glyph {
+	if g, found := font[r]; found {
+		return g
+	}
+	return font['?']
+}
+
+func textWidth(text string, scale int) int {
+	count := len([]rune(fontReplacer.Replace(text)))
+	if count == 0 {
+		return 0
+	}
+	return (count*(glyphWidth+glyphSpacing) - glyphSpacing) * scale
+}
+
+// Draws `text` with its top left corner at x, y
+func drawText(dst draw.Image, x int, y int, text string, scale int, c color.Color) {
+	src := image.NewUniform(c)
+	for _, r := range fontReplacer.Replace(text) {
+		g := glyphFor(r)
+		for row, bits := range g {
+			for col := range glyphWidth {
+				if bits&(1<<(glyphWidth-1-col)) == 0 {
+					continue
+				}
+				pixel := image.Rect(
+					x+col*scale, y+row*scale,
+					x+(col+1)*scale, y+(row+1)*scale,
+				)
+				draw.Draw(dst, pixel, src, image.Point{}, draw.Src)
+			}
+		}
+		x += (glyphWidth + glyphSpacing) * scale
+	}
+}
+
+// Greedy word wrap by character count, since every glyph is the same width
+func wrapText(text string, maxChars int) []string {
+	lines := make([]string, 0, 4)
+	line := ""
+	for _, word := range strings.Fields(fontReplacer.Replace(text)) {
+		for len([]rune(word)) > maxChars {
+			runes := []rune(word)
+			if line != "" {
+				lines = append(lines, line)
+				line = ""
+			}
+			lines = append(lines, string(runes[:maxChars]))
+			word = string(runes[maxChars:])
+		}
+		if line == "" {
+			line = word
+		} else if len([]rune(line))+1+len([]rune(word)) <= maxChars {
+			line += " " + word
+		} else {
+			lines = append(lines, line)
+			line = word
+		}
+	}
+	if line != "" {
+		lines = append(lines, line)
+	}
+	return lines
+}
@@ -8,7 +8,10 @@ import (
 	"net/http"
 	"os"
 	"path"
+	"slices"
 	"strconv"
+	"strings"
+	"sync"
 
 	"compress/gzip"
 	. "libble/shared"
@@ -26,6 +29,16 @@ var (
 )
 
 const saveDir = "saves/"
+const siteOrigin = "https://libble.you"
+
+// One mutex per player, so requests that load, change and save a save file don't overwrite each other
+var saveLocks sync.Map
+
+func lockSave(userID DBID) (unlock func()) {
+	mu, _ := saveLocks.LoadOrStore(userID, &sync.Mutex{})
+	mu.(*sync.Mutex).Lock()
+	return mu.(*sync.Mutex).Unlock
+}
 
 func main() {
 
@@ -42,34 +55,24 @@ func main() {
 	r := gin.Default()
 
 	corsConf := cors.DefaultConfig()
-	corsConf.AllowOrigins = []string{"https://libble.you"}
+	corsConf.AllowOrigins = []string{siteOrigin}
+	corsConf.AddAllowHeaders("Authorization")
 	if isDebug {
 		corsConf.AllowAllOrigins = true
 	}
 
+	assets := embeddedSiteAssets()
 	r.Use(
-		ginzip.Gzip(ginzip.DefaultCompression),
+		ginzip.Gzip(ginzip.DefaultCompression, ginzip.WithCustomShouldCompressFn(func(c *gin.Context) bool {
+			return strings.Contains(c.GetHeader("Accept-Encoding"), "gzip") && !assets.precompressed(c)
+		})),
 		cors.New(corsConf),
 	)
 
 	r.SetTrustedProxies(nil)
 
-	// Host the site as well when debugging
-	if isDebug {
-		const siteDir = "./site"
-		if entries, err := os.ReadDir(siteDir); err == nil {
-			for _, entry := range entries {
-				name := entry.Name()
-				if entry.IsDir() {
-					r.Static("/"+name, path.Join(siteDir, name))
-				} else {
-					r.StaticFile(name, path.Join(siteDir, name))
-				}
-			}
-		} else {
-			logg.Errorf("Failed reading from %s:\n%v", siteDir, err)
-		}
-	}
+	// Host the site as well when it's embedded or when debugging
+	hostSite(r, assets, isDebug)
 
 	if err := os.MkdirAll(saveDir, os.ModePerm); err != nil {
 		logg.Errorf("Failed making save dir: %v", err)
@@ -86,6 +89,12 @@ func main() {
 			return
 		}
 
+		timezone := c.Query("timezone")
+		if timezone != "" && !ValidTimezone(timezone) {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Unknown timezone " + timezone})
+			return
+		}
+
 		// TODO: Maybe limit to 3 per user?
 
 		books, quotes, err := scrapeGoodreads(userGRID, options)
@@ -95,42 +104,174 @@ func main() {
 			return
 		}
 
-		saveData := createUserData(userGRID, books, quotes)
+		// Saves from before tokens existed can't be claimed, the owner moves to a new save instead.
+		// Only the library is carried over so the ids their games point at still work,
+		// the games themselves come from the owner's device when it syncs.
+		var saveData SaveData
+		if from := c.Query("from"); from != "" {
+			if legacyID, err := strconv.ParseUint(from, 10, 64); err == nil {
+				unlock := lockSave(DBID(legacyID))
+				legacy, err := loadUserData(DBID(legacyID))
+				unlock()
+				if err == nil && legacy.Player.Token == "" && legacy.Player.UserGRID == userGRID {
+					saveData.Books = legacy.Books
+					saveData.Quotes = legacy.Quotes
+				}
+			}
+		}
+
+		saveData, err = createUserData(saveData, userGRID, timezone, books, quotes)
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+			return
+		}
 		c.JSON(http.StatusOK, saveData)
 	})
 
-	// TODO: update save data? I could just use the scrape request
-	// r.POST("/update/:id", func(c *gin.Context) {}
-
+	// Scrapes the library again and merges it into the save, keeping ids
 	r.GET("/update/:id", func(c *gin.Context) {
-		userIDParam := c.Param("id")
-		if userIDParam == "" {
-			c.JSON(http.StatusBadRequest, gin.H{"error": "Must provide user id param"})
+		userID, ok := userIDParam(c)
+		if !ok {
 			return
 		}
 
-		userID, err := strconv.ParseUint(userIDParam, 10, 64)
+		saveData, err := loadUserData(userID)
 		if err != nil {
-			c.JSON(http.StatusBadRequest, gin.H{"error": "Must provide valid user id param"})
+			errMsg := fmt.Sprintf("Failed loading user data: %v", err)
+			c.JSON(http.StatusBadRequest, gin.H{"error": errMsg})
+			return
+		}
+		if !authorized(c, saveData.Player) {
 			return
 		}
 
-		saveData, err := loadUserData(DBID(userID))
+		userGRID := saveData.Player.UserGRID
+		books, quotes, err := scrapeGoodreads(userGRID, options)
+		if err != nil {
+			errorMsg := fmt.Sprintf("Error scraping goodreads with id %s: %v", userGRID, err)
+			c.JSON(http.StatusFailedDependency, gin.H{"error": errorMsg})
+			return
+		}
+
+		// Scraping takes minutes and the player may have synced games meanwhile, so load it again
+		unlock := lockSave(userID)
+		defer unlock()
+		saveData, err = loadUserData(userID)
 		if err != nil {
 			errMsg := fmt.Sprintf("Failed loading user data: %v", err)
 			c.JSON(http.StatusBadRequest, gin.H{"error": errMsg})
 			return
 		}
 
-		userGRID := saveData.Player.UserGRID
-		_, _, err = scrapeGoodreads(userGRID, options)
+		update := saveData.MergeLibrary(books, quotes)
+		if err := saveUserData(saveData); err != nil {
+			errMsg := fmt.Sprintf("Failed saving user data: %v", err)
+			c.JSON(http.StatusInternalServerError, gin.H{"error": errMsg})
+			return
+		}
+		logg.Infof("Refreshed library for %s with %d new books and %d new quotes",
+			userGRID, update.NewBooks, update.NewQuotes)
+		c.JSON(http.StatusOK, update)
+	})
+
+	// Lets other clients, like the terminal one or a paired device, play with the same save
+	r.GET("/save/:id", func(c *gin.Context) {
+		userID, ok := userIDParam(c)
+		if !ok {
+			return
+		}
+
+		saveData, err := loadUserData(userID)
 		if err != nil {
-			errorMsg := fmt.Sprintf("Error scraping goodreads with id %s: %v", userGRID, err)
-			c.JSON(http.StatusFailedDependency, gin.H{"error": errorMsg})
+			errMsg := fmt.Sprintf("Failed loading user data: %v", err)
+			c.JSON(http.StatusBadRequest, gin.H{"error": errMsg})
+			return
+		}
+		if !authorized(c, saveData.Player) {
+			return
+		}
+		c.JSON(http.StatusOK, saveData)
+	})
+
+	r.PUT("/player/:id", func(c *gin.Context) {
+		userID, ok := userIDParam(c)
+		if !ok {
+			return
+		}
+
+		var player Player
+		if err := c.ShouldBindJSON(&player); err != nil {
+			errMsg := fmt.Sprintf("Failed reading player data: %v", err)
+			c.JSON(http.StatusBadRequest, gin.H{"error": errMsg})
+			return
+		}
+
+		unlock := lockSave(userID)
+		defer unlock()
+		saveData, err := loadUserData(userID)
+		if err != nil {
+			errMsg := fmt.Sprintf("Failed loading user data: %v", err)
+			c.JSON(http.StatusBadRequest, gin.H{"error": errMsg})
+			return
+		}
+		if !authorized(c, saveData.Player) {
 			return
 		}
+
+		// The client can't change who it is
+		player.ID = saveData.Player.ID
+		player.UserGRID = saveData.Player.UserGRID
+		player.Token = saveData.Player.Token
+		if !ValidTimezone(player.Timezone) {
+			player.Timezone = saveData.Player.Timezone
+		}
+		if !ValidBookCooldownDays(player.BookCooldownDays) {
+			errMsg := fmt.Sprintf("Book cooldown has to be between 0 and %d days", MaxBookCooldownDays)
+			c.JSON(http.StatusBadRequest, gin.H{"error": errMsg})
+			return
+		}
+		// Other clients may have played since this one loaded the save
+		saveData.Player = saveData.MergePlayer(player)
+		saveData.Migrate()
+
+		if err := saveUserData(saveData); err != nil {
+			errMsg := fmt.Sprintf("Failed saving user data: %v", err)
+			c.JSON(http.StatusInternalServerError, gin.H{"error": errMsg})
+			return
+		}
+		c.JSON(http.StatusOK, gin.H{})
 	})
 
+	r.GET("/stats/:id", func(c *gin.Context) {
+		userID, ok := userIDParam(c)
+		if !ok {
+			return
+		}
+
+		mode := GameMode(c.Query("mode"))
+		if !slices.Contains(GameModes, mode) {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Unknown game mode " + string(mode)})
+			return
+		}
+
+		saveData, err := loadUserData(userID)
+		if err != nil {
+			errMsg := fmt.Sprintf("Failed loading user data: %v", err)
+			c.JSON(http.StatusBadRequest, gin.H{"error": errMsg})
+			return
+		}
+		// Accuracy per author and year gives away what they've read
+		if !authorized(c, saveData.Player) {
+			return
+		}
+		c.JSON(http.StatusOK, saveData.Stats(mode))
+	})
+
+	setupPairing(r, assets != nil || isDebug)
+
+	r.GET("/card/:id/:day", cardHandler)
+	r.GET("/share/:id/:day", shareHandler)
+
 	r.GET("/scrape/:id", func(c *gin.Context) {
 		userGRID := c.Param("id")
 		if userGRID == "" {
@@ -152,16 +293,33 @@ func main() {
 	logg.Fatal(r.Run())
 }
 
+// Writes the error response itself when the id is missing or invalid
+func userIDParam(c *gin.Context) (DBID, bool) {
+	userIDParam := c.Param("id")
+	if userIDParam == "" {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Must provide user id param"})
+		return 0, false
+	}
+
+	userID, err := strconv.ParseUint(userIDParam, 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Must provide valid user id param"})
+		return 0, false
+	}
+	return DBID(userID), true
+}
+
 func saveFileName(userID DBID) string {
 	return strconv.FormatUint(uint64(userID), 10)
 }
 
 func saveUserData(save SaveData) error {
 	fileName := saveFileName(save.Player.ID)
-	file, err := os.OpenFile(path.Join(saveDir, fileName), os.O_CREATE|os.O_WRONLY, 0644)
+	file, err := os.OpenFile(path.Join(saveDir, fileName), os.O_CREATE|os.O_WRONLY|os.O_TRUNC, 0644)
 	if err != nil {
 		return fmt.Errorf("Failed opening save file: %v", err)
 	}
+	defer file.Close()
 
 	saveBytes, err := json.Marshal(save)
 	if err != nil {
@@ -211,63 +369,23 @@ func loadUserData(userID DBID) (SaveData, error) {
 	if err := decoder.Decode(&data); err != nil {
 		return data, fmt.Errorf("Failed decoding save data: %v", err)
 	}
+	data.Migrate()
 
 	return data, nil
 }
 
-func createUserData(userGRID string, books []UserBook, quotes []Quote) SaveData {
-	var data SaveData
+// Adds the scraped library to data, which only needs books and quotes when they're carried over
+func createUserData(data SaveData, userGRID string, timezone string, books []UserBook, quotes []Quote) (SaveData, error) {
+	token, err := newToken()
+	if err != nil {
+		return data, err
+	}
 	data.Player.UserGRID = userGRID
+	data.Player.Timezone = timezone
 	data.Player.ID = DBID(rand.Uint64())
+	data.Player.Token = token
 
-	// Initialize maps
-	data.Books = make(map[BookId]UserBook)
-	data.Quotes = make(map[QuoteId]Quote)
-
-	bookGRIDtoID := make(map[string]BookId)
-
-	// Populate books map
-	for _, book := range books {
-		bookID := BookId(rand.Uint64())
-		if _, exists := data.Books[bookID]; exists {
-			logg.Errorf("Generated unique book id that wasn't unique")
-		}
-		exists := func() bool {
-			_, found := data.Books[bookID]
-			return found
-		}
-		for exists() {
-			logg.Errorf("Generated unique quote id that wasn't unique")
-			bookID = BookId(rand.Uint64())
-		}
-
-		data.Books[bookID] = book
-		bookGRIDtoID[book.Book.BookGRID] = bookID
-	}
-
-	// Populate quotes map
-	for _, quote := range quotes {
-		quoteID := QuoteId(rand.Uint64())
-
-		exists := func() bool {
-			_, found := data.Quotes[quoteID]
-			return found
-		}
-		for exists() {
-			logg.Errorf("Generated unique quote id that wasn't unique")
-			quoteID = QuoteId(rand.Uint64())
-		}
-
-		if quote.BookGRID != "" {
-			if bookID, found := bookGRIDtoID[quote.BookGRID]; found {
-				quote.BookId = bookID
-			} else {
-				logg.Errorf("BookGRID %s exists on quote %d but wasn't found", quote.BookGRID, quoteID)
-			}
-		}
-
-		data.Quotes[quoteID] = quote
-	}
+	data.MergeLibrary(books, quotes)
 
 	// Initialize empty slices
 	data.Player.SeenQuotes = []QuoteId{}
@@ -277,5 +395,5 @@ func createUserData(userGRID string, books []UserBook, quotes []Quote) SaveData
 		logg.Errorf("Unabled to save new user data: %v", err)
 	}
 
-	return data
+	return data, nil
 }
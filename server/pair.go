@@ -0,0 +1,211 @@
+package main
+
+import (
+	"crypto/rand"
+	"crypto/subtle"
+	"encoding/hex"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+
+	. "libble/shared"
+
+	"github.com/gin-gonic/gin"
+	"github.com/skip2/go-qrcode"
+)
+
+const (
+	pairCodeLength = 6
+	pairCodeTTL    = 10 * time.Minute
+	// No 0/O or 1/I, so codes can be read off one screen and typed into another
+	pairCodeAlphabet = "ABCDEFGHJKLMNPQRSTUVWXYZ23456789"
+)
+
+type pairing struct {
+	userID    DBID
+	expiresAt time.Time
+}
+
+// Codes only live in memory, a restart just means asking for a new one
+type pairings struct {
+	mu    sync.Mutex
+	codes map[string]pairing
+}
+
+func newPairings() *pairings {
+	return &pairings{codes: make(map[string]pairing)}
+}
+
+func (p *pairings) issue(userID DBID) (string, time.Time, error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.removeExpired()
+
+	expiresAt := time.Now().Add(pairCodeTTL)
+	for {
+		code, err := newPairCode()
+		if err != nil {
+			return "", expiresAt, err
+		}
+		if _, taken := p.codes[code]; taken {
+			continue
+		}
+		p.codes[code] = pairing{userID: userID, expiresAt: expiresAt}
+		return code, expiresAt, nil
+	}
+}
+
+// Codes can only be redeemed once
+func (p *pairings) redeem(code string) (DBID, bool) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.removeExpired()
+
+	code = normalizePairCode(code)
+	found, ok := p.codes[code]
+	delete(p.codes, code)
+	return found.userID, ok
+}
+
+func (p *pairings) active(code string) bool {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.removeExpired()
+	_, ok := p.codes[normalizePairCode(code)]
+	return ok
+}
+
+func (p *pairings) removeExpired() {
+	now := time.Now()
+	for code, found := range p.codes {
+		if now.After(found.expiresAt) {
+			delete(p.codes, code)
+		}
+	}
+}
+
+func newPairCode() (string, error) {
+	b := make([]byte, pairCodeLength)
+	if _, err := rand.Read(b); err != nil {
+		return "", fmt.Errorf("Failed generating pairing code: %v", err)
+	}
+	for i := range b {
+		b[i] = pairCodeAlphabet[int(b[i])%len(pairCodeAlphabet)] // 256 is a multiple of 32, so no bias
+	}
+	return string(b), nil
+}
+
+func normalizePairCode(code string) string {
+	return strings.ToUpper(strings.ReplaceAll(strings.TrimSpace(code), "-", ""))
+}
+
+// Secret that proves a client owns a save, handed out when the player is created or paired
+func newToken() (string, error) {
+	b := make([]byte, 32)
+	if _, err := rand.Read(b); err != nil {
+		return "", fmt.Errorf("Failed generating token: %v", err)
+	}
+	return hex.EncodeToString(b), nil
+}
+
+func bearerToken(c *gin.Context) string {
+	token, _ := strings.CutPrefix(c.GetHeader("Authorization"), "Bearer ")
+	return strings.TrimSpace(token)
+}
+
+// Writes the error response itself when the request doesn't carry the player's token
+func authorized(c *gin.Context, player Player) bool {
+	token := bearerToken(c)
+	if player.Token == "" || subtle.ConstantTimeCompare([]byte(token), []byte(player.Token)) != 1 {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Missing or wrong token for this player"})
+		return false
+	}
+	return true
+}
+
+// The start page with the code filled in, what the QR code points at
+func pairPageURL(c *gin.Context, hostsSite bool, code string) string {
+	origin := siteOrigin
+	if hostsSite {
+		scheme := "http"
+		if c.Request.TLS != nil {
+			scheme = "https"
+		}
+		origin = scheme + "://" + c.Request.Host
+	}
+	return origin + "/start.html?" + url.Values{"pair": {code}}.Encode()
+}
+
+func setupPairing(r *gin.Engine, hostsSite bool) {
+	codes := newPairings()
+
+	// Gives a code another device can redeem for this player
+	r.POST("/pair/:id", func(c *gin.Context) {
+		userID, ok := userIDParam(c)
+		if !ok {
+			return
+		}
+
+		saveData, err := loadUserData(userID)
+		if err != nil {
+			errMsg := fmt.Sprintf("Failed loading user data: %v", err)
+			c.JSON(http.StatusBadRequest, gin.H{"error": errMsg})
+			return
+		}
+		if !authorized(c, saveData.Player) {
+			return
+		}
+
+		code, expiresAt, err := codes.issue(userID)
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+			return
+		}
+		c.JSON(http.StatusOK, gin.H{
+			"code":       code,
+			"expires_at": expiresAt,
+			"url":        pairPageURL(c, hostsSite, code),
+		})
+	})
+
+	// Only shows codes that are still active so it can't be used to make arbitrary QR codes
+	r.GET("/pair/:code/qr.png", func(c *gin.Context) {
+		code := c.Param("code")
+		if !codes.active(code) {
+			c.JSON(http.StatusNotFound, gin.H{"error": "Pairing code expired or doesn't exist"})
+			return
+		}
+
+		png, err := qrcode.Encode(pairPageURL(c, hostsSite, normalizePairCode(code)), qrcode.Medium, 256)
+		if err != nil {
+			errMsg := fmt.Sprintf("Failed making QR code: %v", err)
+			c.JSON(http.StatusInternalServerError, gin.H{"error": errMsg})
+			return
+		}
+		c.Header("Cache-Control", "no-store")
+		c.Data(http.StatusOK, "image/png", png)
+	})
+
+	r.POST("/redeem/:code", func(c *gin.Context) {
+		userID, ok := codes.redeem(c.Param("code"))
+		if !ok {
+			c.JSON(http.StatusNotFound, gin.H{"error": "Pairing code expired or doesn't exist"})
+			return
+		}
+
+		saveData, err := loadUserData(userID)
+		if err != nil {
+			errMsg := fmt.Sprintf("Failed loading user data: %v", err)
+			c.JSON(http.StatusBadRequest, gin.H{"error": errMsg})
+			return
+		}
+		logg.Infof("Paired a new device for %s", saveData.Player.UserGRID)
+		c.JSON(http.StatusOK, gin.H{
+			"libble_id": saveData.Player.ID,
+			"token":     saveData.Player.Token,
+		})
+	})
+}
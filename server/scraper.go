@@ -3,6 +3,7 @@ package main
 import (
 	"fmt"
 	"net/url"
+	"slices"
 	"strconv"
 	"strings"
 	"sync"
@@ -16,8 +17,18 @@ const (
 	domain        = "www.goodreads.com"
 	requestCache  = "./.request_cache"
 	minQuoteLikes = 100
+	// Across every scrape at once, so signups and refreshes together stay polite to goodreads
+	maxConcurrentPages = 4
 )
 
+var pageSlots = make(chan struct{}, maxConcurrentPages)
+
+// Blocks until there's room for another page to be scraped, call the returned func when done
+func takePageSlot() func() {
+	pageSlots <- struct{}{}
+	return func() { <-pageSlots }
+}
+
 type ScrapeOptions struct {
 	cache bool
 }
@@ -35,19 +46,41 @@ func scrapeGoodreads(userGRID string, options ScrapeOptions) ([]UserBook, []Quot
 	readCount := 0
 	quotes := make([]Quote, 0, 100)
 
+	needsPage := booksNeedingPages(books)
+
 	var wg sync.WaitGroup
 	var mutex sync.Mutex
-	for _, userBook := range books {
+	for i, userBook := range books {
+		book := userBook.Book
+
+		if needsPage[i] {
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				defer takePageSlot()()
+
+				page, err := scrapeBookPage(book.BookGRID, options)
+				if err != nil {
+					logg.Error(err)
+					return
+				}
+
+				mutex.Lock()
+				defer mutex.Unlock()
+				books[i].Book.WorkGRID = page.WorkGRID
+				books[i].Book.Characters = page.Characters
+			}()
+		}
+
 		if !userBook.UserData.ShouldScrape() {
 			continue
 		}
 
-		book := userBook.Book
-
 		readCount += 1
 		wg.Add(1)
 		go func() {
 			defer wg.Done()
+			defer takePageSlot()()
 
 			url := "https://" + domain + "/book/quotes/" + book.BookGRID
 			bookQuotes, err := scrapeQuotes(url, book.BookGRID, options)
@@ -71,6 +104,24 @@ func scrapeGoodreads(userGRID string, options ScrapeOptions) ([]UserBook, []Quot
 	return books, quotes, err
 }
 
+// Book pages have the characters and the work id. Characters are only needed for books that get
+// quotes, and the work id only for books that could be another edition of one on the shelf.
+func booksNeedingPages(books []UserBook) []bool {
+	editionKey := func(book Book) string {
+		return book.AuthorGRID + "/" + strings.ToLower(book.BaseTitle)
+	}
+	editionCounts := make(map[string]int)
+	for _, userBook := range books {
+		editionCounts[editionKey(userBook.Book)] += 1
+	}
+
+	needsPage := make([]bool, len(books))
+	for i, userBook := range books {
+		needsPage[i] = userBook.UserData.ShouldScrape() || editionCounts[editionKey(userBook.Book)] > 1
+	}
+	return needsPage
+}
+
 func scrapeForNextPage(e *colly.HTMLElement) string {
 	if href := e.Attr("href"); href != "" {
 		nextPageUrl, err := url.Parse(href)
@@ -136,8 +187,8 @@ func defaultCollectorOptions(options ScrapeOptions) func(*colly.Collector) {
 		if options.cache {
 			c.CacheDir = requestCache
 		}
-		colly.AllowedDomains(domain)
-		colly.Async(true)
+		// Collectors are used synchronously, the goroutines in scrapeGoodreads do the waiting
+		colly.AllowedDomains(domain)(c)
 	}
 }
 
@@ -155,6 +206,7 @@ func scrapeBook(bookElem *colly.HTMLElement) (UserBook, error) {
 		switch class {
 		case "title":
 			book.Title = fieldElem.ChildAttr("a", "title")
+			book.ParseTitle()
 			book.BookGRID = parseGRID(fieldElem.ChildAttr("a", "href"))
 		case "author":
 			book.Author = fieldElem.ChildText("a")
@@ -210,6 +262,61 @@ func scrapeBook(bookElem *colly.HTMLElement) (UserBook, error) {
 	return userBook, fmt.Errorf("Failed to scrape the book")
 }
 
+type bookPage struct {
+	WorkGRID   string
+	Characters []string
+}
+
+func scrapeBookPage(bookGRID string, options ScrapeOptions) (bookPage, error) {
+	pageCollector := colly.NewCollector(
+		defaultCollectorOptions(options),
+	)
+
+	page := bookPage{Characters: make([]string, 0, 8)}
+
+	pageCollector.OnError(func(r *colly.Response, err error) {
+		logg.Errorf("Error when collecting book page at %v\n%v", r.Request.URL, err)
+	})
+
+	pageCollector.OnHTML(`a[href*="/characters/"]`, func(characterElem *colly.HTMLElement) {
+		name := strings.Join(strings.Fields(characterElem.Text), " ")
+		if name != "" && !slices.Contains(page.Characters, name) {
+			page.Characters = append(page.Characters, name)
+		}
+	})
+
+	// The book's own "all editions" link, e.g. /work/editions/3462456-the-fellowship-of-the-ring.
+	// Other works only link to their /book/show/ pages, but if the page somehow points at
+	// more than one work it's safer not to group this book with anything.
+	workGRIDs := make([]string, 0, 1)
+	pageCollector.OnHTML(`a[href*="/work/editions/"]`, func(workElem *colly.HTMLElement) {
+		if grid := parseWorkGRID(workElem.Attr("href")); grid != "" && !slices.Contains(workGRIDs, grid) {
+			workGRIDs = append(workGRIDs, grid)
+		}
+	})
+
+	url := "https://" + domain + "/book/show/" + bookGRID
+	if err := pageCollector.Visit(url); err != nil {
+		return page, fmt.Errorf("Failed scraping book page for %s: %v", bookGRID, err)
+	}
+	if len(workGRIDs) == 1 {
+		page.WorkGRID = workGRIDs[0]
+	} else if len(workGRIDs) > 1 {
+		logg.Warnf("Book %s links to several works %v, not grouping its editions", bookGRID, workGRIDs)
+	}
+	return page, nil
+}
+
+// Just the number, since work links end with either the id or id-title
+func parseWorkGRID(href string) string {
+	grid := parseGRID(strings.TrimSuffix(href, "/"))
+	end := strings.IndexFunc(grid, func(r rune) bool { return r < '0' || r > '9' })
+	if end >= 0 {
+		grid = grid[:end]
+	}
+	return grid
+}
+
 func scrapeQuotes(url string, bookGRID string, options ScrapeOptions) ([]Quote, error) {
 	quoteCollector := colly.NewCollector(
 		defaultCollectorOptions(options),
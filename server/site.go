@@ -0,0 +1,165 @@
+package main
+
+import (
+	"bytes"
+	"compress/gzip"
+	"crypto/sha256"
+	"encoding/hex"
+	"io/fs"
+	"mime"
+	"net/http"
+	"os"
+	"path"
+	"slices"
+	"strings"
+
+	"libble/site"
+
+	"github.com/gin-gonic/gin"
+)
+
+const siteDir = "./site"
+
+// A file from the embedded site, prepared once so requests only copy bytes
+type siteAsset struct {
+	contentType  string
+	cacheControl string
+	etag         string
+	body         []byte
+	gzipped      []byte // nil when compressing doesn't help
+}
+
+// Keyed by url path
+type siteAssets map[string]siteAsset
+
+// Files that have to be checked every time so new versions of the site get picked up
+var revalidatedFiles = []string{"sw.js", "sw-version.js", "config.json"}
+
+func loadSiteAssets(files fs.FS) (siteAssets, error) {
+	assets := make(siteAssets)
+	err := fs.WalkDir(files, ".", func(name string, entry fs.DirEntry, err error) error {
+		if err != nil || entry.IsDir() || strings.HasSuffix(name, ".go") {
+			return err
+		}
+		body, err := fs.ReadFile(files, name)
+		if err != nil {
+			return err
+		}
+
+		ext := path.Ext(name)
+		asset := siteAsset{
+			contentType:  mime.TypeByExtension(ext),
+			cacheControl: "public, max-age=3600",
+			body:         body,
+		}
+		if ext == ".wasm" {
+			asset.contentType = "application/wasm" // Required by instantiateStreaming
+		} else if asset.contentType == "" {
+			asset.contentType = http.DetectContentType(body)
+		}
+		if ext == ".html" || slices.Contains(revalidatedFiles, path.Base(name)) {
+			asset.cacheControl = "no-cache"
+		}
+
+		sum := sha256.Sum256(body)
+		asset.etag = `"` + hex.EncodeToString(sum[:8]) + `"`
+
+		var compressed bytes.Buffer
+		compresser, _ := gzip.NewWriterLevel(&compressed, gzip.BestCompression)
+		if _, err := compresser.Write(body); err != nil {
+			return err
+		}
+		if err := compresser.Close(); err != nil {
+			return err
+		}
+		if compressed.Len() < len(body)*9/10 {
+			asset.gzipped = compressed.Bytes()
+		}
+
+		assets["/"+name] = asset
+		return nil
+	})
+	return assets, err
+}
+
+func (a siteAssets) find(urlPath string) (siteAsset, bool) {
+	if strings.HasSuffix(urlPath, "/") {
+		urlPath += "index.html"
+	}
+	asset, found := a[urlPath]
+	return asset, found
+}
+
+// Whether the asset is served here, the gzip middleware has to leave these alone
+func (a siteAssets) precompressed(c *gin.Context) bool {
+	_, found := a.find(c.Request.URL.Path)
+	return found
+}
+
+func (a siteAssets) serve(c *gin.Context) {
+	asset, found := a.find(c.Request.URL.Path)
+	if !found || (c.Request.Method != http.MethodGet && c.Request.Method != http.MethodHead) {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Not found"})
+		return
+	}
+
+	header := c.Writer.Header()
+	header.Set("Cache-Control", asset.cacheControl)
+	header.Set("ETag", asset.etag)
+	header.Add("Vary", "Accept-Encoding")
+	if c.GetHeader("If-None-Match") == asset.etag {
+		c.Status(http.StatusNotModified)
+		return
+	}
+
+	body := asset.body
+	if asset.gzipped != nil && strings.Contains(c.GetHeader("Accept-Encoding"), "gzip") {
+		header.Set("Content-Encoding", "gzip")
+		body = asset.gzipped
+	}
+	c.Data(http.StatusOK, asset.contentType, body)
+}
+
+// Serves the embedded site when the server was built with it, or ./site when debugging
+func hostSite(r *gin.Engine, assets siteAssets, isDebug bool) {
+	if assets != nil {
+		r.NoRoute(assets.serve)
+	} else if isDebug {
+		if entries, err := os.ReadDir(siteDir); err == nil {
+			for _, entry := range entries {
+				name := entry.Name()
+				if name == "config.json" || strings.HasSuffix(name, ".go") {
+					continue // config.json is served below so the site talks to this server
+				}
+				if entry.IsDir() {
+					r.Static("/"+name, path.Join(siteDir, name))
+				} else {
+					r.StaticFile(name, path.Join(siteDir, name))
+				}
+			}
+		} else {
+			logg.Errorf("Failed reading from %s:\n%v", siteDir, err)
+		}
+	} else {
+		return
+	}
+
+	// An empty origin tells the client to use whatever origin it was served from
+	r.GET("/config.json", func(c *gin.Context) {
+		c.Header("Cache-Control", "no-cache")
+		c.JSON(http.StatusOK, gin.H{"api_origin": ""})
+	})
+}
+
+// The embedded site, nil when the server wasn't built with it
+func embeddedSiteAssets() siteAssets {
+	if site.Files == nil {
+		return nil
+	}
+	assets, err := loadSiteAssets(site.Files)
+	if err != nil {
+		logg.Fatalf("Failed loading the embedded site: %v", err)
+	}
+	logg.Infof("Serving %d embedded site files", len(assets))
+	return assets
+}
@@ -0,0 +1,49 @@
+package shared
+
+import (
+	"time"
+)
+
+// How many past days the archive lists
+const ArchiveLength = 30
+
+type DayStatus string
+
+const (
+	UnplayedDay   DayStatus = "unplayed"
+	InProgressDay DayStatus = "in_progress"
+	WonDay        DayStatus = "won"
+	LostDay       DayStatus = "lost"
+)
+
+type ArchiveDay struct {
+	Day     string    `json:"day"`
+	Status  DayStatus `json:"status"`
+	Archive bool      `json:"archive"` // Played after the day was over
+}
+
+// Past days for a mode starting from yesterday, most recent first
+func (s SaveData) ArchiveDays(mode GameMode) []ArchiveDay {
+	days := make([]ArchiveDay, 0, ArchiveLength)
+	today := time.Now().In(s.Player.Location())
+	for i := 1; i <= ArchiveLength; i++ {
+		day := ArchiveDay{
+			Day:    today.AddDate(0, 0, -i).Format(DayLayout),
+			Status: UnplayedDay,
+		}
+		if game := s.Player.DailyGame(day.Day, mode); game != nil {
+			day.Archive = game.Archive
+			if err := game.Init(s); err != nil {
+				day.Status = InProgressDay
+			} else if game.Won() {
+				day.Status = WonDay
+			} else if game.Completed() {
+				day.Status = LostDay
+			} else {
+				day.Status = InProgressDay
+			}
+		}
+		days = append(days, day)
+	}
+	return days
+}
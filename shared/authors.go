@@ -0,0 +1,92 @@
+package shared
+
+import (
+	"math/rand"
+	"slices"
+	"strings"
+)
+
+type Author struct {
+	GRID      string `json:"author_gr_id"`
+	Name      string `json:"name"`
+	BookCount int    `json:"book_count"`
+}
+
+type Authors []Author
+
+// Every author in the library sorted by name, used for autocomplete
+func (s SaveData) Authors() Authors {
+	byGRID := make(map[string]int)
+	authors := make(Authors, 0, len(s.Books))
+	for _, book := range s.Books {
+		grid := book.Book.AuthorGRID
+		if index, found := byGRID[grid]; found {
+			authors[index].BookCount += 1
+			continue
+		}
+		byGRID[grid] = len(authors)
+		authors = append(authors, Author{
+			GRID:      grid,
+			Name:      book.Book.Author,
+			BookCount: 1,
+		})
+	}
+	slices.SortFunc(authors, func(a Author, b Author) int {
+		if c := strings.Compare(a.Name, b.Name); c != 0 {
+			return c
+		}
+		return strings.Compare(a.GRID, b.GRID)
+	})
+	return authors
+}
+
+func (a Authors) String(i int) string {
+	if i >= 0 && i < len(a) {
+		return a[i].Name
+	}
+	return ""
+}
+
+func (a Authors) Len() int {
+	return len(a)
+}
+
+func (a Authors) Find(query string) (Author, bool) {
+	query = strings.ToLower(strings.TrimSpace(query))
+	for _, author := range a {
+		if strings.ToLower(author.Name) == query {
+			return author, true
+		}
+	}
+	return Author{}, false
+}
+
+// Picks an author first so big single-author collections don't crowd out everyone else
+func (s SaveData) pickAuthorQuote(rng *rand.Rand, skipBooks map[BookId]bool) (QuoteId, bool) {
+	quotesByAuthor := make(map[string][]QuoteId)
+	for quoteId, quote := range s.Quotes {
+		if slices.Contains(s.Player.SeenQuotes, quoteId) {
+			continue
+		}
+		book, found := s.Books[quote.BookId]
+		if !found || !book.UserData.IsRead() || skipBooks[quote.BookId] {
+			continue
+		}
+		grid := book.Book.AuthorGRID
+		quotesByAuthor[grid] = append(quotesByAuthor[grid], quoteId)
+	}
+	if len(quotesByAuthor) == 0 {
+		return NilID, false
+	}
+
+	// Sorted so the same seed always gives the same pick
+	authors := make([]string, 0, len(quotesByAuthor))
+	for grid := range quotesByAuthor {
+		authors = append(authors, grid)
+	}
+	slices.Sort(authors)
+
+	quoteIds := quotesByAuthor[authors[rng.Intn(len(authors))]]
+	slices.Sort(quoteIds)
+	return quoteIds[rng.Intn(len(quoteIds))], true
+}
@@ -0,0 +1,54 @@
+package shared
+
+import (
+	"slices"
+	"strings"
+)
+
+type BookOption struct {
+	Id   BookId
+	Book Book
+}
+
+// Books the player can guess, kept with their ids so picking a suggestion picks that exact book
+type Books []BookOption
+
+// Every book in the library sorted by title, used for autocomplete
+func (s SaveData) SortedBooks() Books {
+	options := make(Books, 0, len(s.Books))
+	for bookId, book := range s.Books {
+		options = append(options, BookOption{Id: bookId, Book: book.Book})
+	}
+	slices.SortFunc(options, func(a, b BookOption) int {
+		if c := strings.Compare(a.Book.CleanTitle(), b.Book.CleanTitle()); c != 0 {
+			return c
+		}
+		return strings.Compare(a.Book.BookGRID, b.Book.BookGRID)
+	})
+	return options
+}
+
+func (b Books) String(i int) string {
+	if i >= 0 && i < len(b) {
+		return b[i].Book.CleanTitle()
+	}
+	return ""
+}
+
+func (b Books) Len() int {
+	return len(b)
+}
+
+// The authors of the books, lined up with the books so it can be searched alongside them
+type BookAuthors Books
+
+func (b BookAuthors) String(i int) string {
+	if i >= 0 && i < len(b) {
+		return b[i].Book.Author
+	}
+	return ""
+}
+
+func (b BookAuthors) Len() int {
+	return len(b)
+}
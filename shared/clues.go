@@ -0,0 +1,155 @@
+package shared
+
+import (
+	"math"
+	"strings"
+	"time"
+)
+
+type ClueKind string
+
+const (
+	AuthorClue     ClueKind = "author"
+	ReadDateClue   ClueKind = "read_date"
+	RatingClue     ClueKind = "avg_rating"
+	PopularityClue ClueKind = "rating_count"
+	SeriesClue     ClueKind = "series"
+)
+
+type ClueResult int8
+
+const (
+	ClueUnknown ClueResult = iota
+	ClueMatch
+	ClueMiss
+	ClueHigher // The answer is higher/later than the guess
+	ClueLower  // The answer is lower/earlier than the guess
+)
+
+func (r ClueResult) String() string {
+	switch r {
+	case ClueMatch:
+		return "match"
+	case ClueMiss:
+		return "miss"
+	case ClueHigher:
+		return "higher"
+	case ClueLower:
+		return "lower"
+	}
+	return "unknown"
+}
+
+type Clue struct {
+	Kind   ClueKind   `json:"kind"`
+	Result ClueResult `json:"result"`
+}
+
+// Compares a wrong guess against the answer, Wordle style
+func CompareBooks(guess UserBook, answer UserBook) []Clue {
+	clues := make([]Clue, 0, 5)
+
+	authorResult := ClueMiss
+	if guess.Book.AuthorGRID == answer.Book.AuthorGRID {
+		authorResult = ClueMatch
+	}
+	clues = append(clues, Clue{AuthorClue, authorResult})
+
+	readResult := ClueUnknown
+	guessRead, guessOk := guess.UserData.LastRead()
+	answerRead, answerOk := answer.UserData.LastRead()
+	if guessOk && answerOk {
+		readResult = compare(float64(answerRead.Unix()), float64(guessRead.Unix()), 0)
+	}
+	clues = append(clues, Clue{ReadDateClue, readResult})
+
+	clues = append(clues, Clue{RatingClue,
+		compare(float64(answer.Book.AvgRating), float64(guess.Book.AvgRating), 0.005)})
+	clues = append(clues, Clue{PopularityClue,
+		compare(float64(answer.Book.RatingCount), float64(guess.Book.RatingCount), 0)})
+
+	seriesResult := ClueMiss
+	if series := answer.Book.Series; series != "" && strings.EqualFold(series, guess.Book.Series) {
+		seriesResult = ClueMatch
+	}
+	clues = append(clues, Clue{SeriesClue, seriesResult})
+
+	return clues
+}
+
+func compare(answer float64, guess float64, tolerance float64) ClueResult {
+	switch {
+	case math.Abs(answer-guess) <= tolerance:
+		return ClueMatch
+	case answer > guess:
+		return ClueHigher
+	default:
+		return ClueLower
+	}
+}
+
+func (c Clue) String() string {
+	switch c.Kind {
+	case AuthorClue:
+		if c.Result == ClueMatch {
+			return "Same author"
+		}
+		return "Different author"
+	case ReadDateClue:
+		switch c.Result {
+		case ClueMatch:
+			return "Read at the same time"
+		case ClueHigher:
+			return "Read later"
+		case ClueLower:
+			return "Read earlier"
+		}
+		return "Read date unknown"
+	case RatingClue:
+		switch c.Result {
+		case ClueMatch:
+			return "Same average rating"
+		case ClueHigher:
+			return "Higher average rating"
+		case ClueLower:
+			return "Lower average rating"
+		}
+	case PopularityClue:
+		switch c.Result {
+		case ClueMatch:
+			return "Same number of ratings"
+		case ClueHigher:
+			return "More ratings"
+		case ClueLower:
+			return "Fewer ratings"
+		}
+	case SeriesClue:
+		if c.Result == ClueMatch {
+			return "Same series"
+		}
+		return "Different series"
+	}
+	return ""
+}
+
+var readDateLayouts = []string{"Jan 02, 2006", "Jan 2, 2006", "Jan 2006", "2006"}
+
+// The most recent date the book was read, if Goodreads has one
+func (b UserBookData) LastRead() (time.Time, bool) {
+	var last time.Time
+	for _, date := range b.DatesRead {
+		if parsed, ok := parseGoodreadsDate(date); ok && parsed.After(last) {
+			last = parsed
+		}
+	}
+	return last, !last.IsZero()
+}
+
+func parseGoodreadsDate(date string) (time.Time, bool) {
+	for _, layout := range readDateLayouts {
+		if parsed, err := time.Parse(layout, strings.TrimSpace(date)); err == nil {
+			return parsed, true
+		}
+	}
+	return time.Time{}, false
+}
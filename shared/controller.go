@@ -0,0 +1,236 @@
+package shared
+
+import (
+	"fmt"
+	"math/rand"
+	"slices"
+	"time"
+)
+
+// How a message should be shown, the values double as css classes on the site
+type FeedbackStatus string
+
+const (
+	NoStatus      FeedbackStatus = ""
+	SuccessStatus FeedbackStatus = "success"
+	ErrorStatus   FeedbackStatus = "error"
+	WarnStatus    FeedbackStatus = "warning"
+)
+
+// Something that happened to the game because of an action
+type Event string
+
+const (
+	GuessedEvent       Event = "guessed"
+	RejectedEvent      Event = "rejected" // The action wasn't taken, the game is unchanged
+	QuoteRevealedEvent Event = "quote_revealed"
+	HintUsedEvent      Event = "hint_used"
+	SkippedEvent       Event = "skipped"
+	CompletedEvent     Event = "completed"
+)
+
+// What an action did, for whatever is showing the game to render
+type Result struct {
+	Events  []Event
+	Message string
+	Status  FeedbackStatus
+	// The guess could mean any of these books, the picked one goes to GuessBook
+	Choices []BookId
+}
+
+func (r Result) Has(event Event) bool {
+	return slices.Contains(r.Events, event)
+}
+
+// Whether the game changed and needs saving
+func (r Result) Changed() bool {
+	return len(r.Events) > 0 && !r.Has(RejectedEvent)
+}
+
+func rejected(message string, status FeedbackStatus) Result {
+	return Result{Events: []Event{RejectedEvent}, Message: message, Status: status}
+}
+
+// Owns the rules for playing a game, so the site and other clients only need to render it
+type Controller struct {
+	Data *SaveData
+	Game *Game
+
+	matcher *TitleMatcher
+	authors Authors
+}
+
+func NewController(data *SaveData, game *Game) *Controller {
+	return &Controller{Data: data, Game: game}
+}
+
+// Call after the books or quotes change so guesses see them
+func (c *Controller) LibraryChanged() {
+	c.matcher = nil
+	c.authors = nil
+}
+
+// Message for a game that can't be played anymore, ok is true while it still can be
+func (c *Controller) Status() (result Result, ok bool) {
+	game := c.Game
+	if !game.Completed() {
+		return Result{}, true
+	}
+	result.Events = []Event{RejectedEvent}
+	if game.Practice {
+		result.Message = "Press Next Quote to keep practicing."
+	} else if game.Day != c.Data.Player.Today() {
+		result.Message = fmt.Sprintf("You already finished %s,\npick another day from the archive.", game.Day)
+	} else if game.Won() {
+		result.Message = "Congrats! You've already won for today, \ncome back tomorrow to play again."
+		result.Status = SuccessStatus
+	} else {
+		result.Message = "Looks like you didn't get it this time :(\nCome back tomorrow and try again!"
+	}
+	return result, false
+}
+
+// Guesses whatever book the text names, asking for a pick when it could be several
+func (c *Controller) GuessTitle(text string) Result {
+	if status, ok := c.Status(); !ok {
+		return status
+	}
+	if c.matcher == nil {
+		matcher := NewTitleMatcher(c.Data.Books)
+		c.matcher = &matcher
+	}
+
+	// Typed guesses are only taken when they name exactly one book
+	matches := c.matcher.Match(text)
+	switch len(matches) {
+	case 0:
+		return rejected("That book is not in your library!", WarnStatus)
+	case 1:
+		return c.GuessBook(matches[0])
+	}
+	result := rejected("That could be a few of your books, which one did you mean?", WarnStatus)
+	result.Choices = matches
+	return result
+}
+
+func (c *Controller) GuessBook(bookId BookId) Result {
+	game := c.Game
+	if status, ok := c.Status(); !ok {
+		return status
+	}
+	if _, found := c.Data.Books[bookId]; !found {
+		return rejected("That book is not in your library!", WarnStatus)
+	}
+	if slices.Contains(game.Guesses, bookId) {
+		return rejected("You already tried that guess!", WarnStatus)
+	}
+
+	game.Guesses = append(game.Guesses, bookId)
+	return c.afterGuess(fmt.Sprintf("\"%s\"", game.Book.Book.CleanTitle()))
+}
+
+func (c *Controller) GuessAuthor(name string) Result {
+	game := c.Game
+	if status, ok := c.Status(); !ok {
+		return status
+	}
+	if c.authors == nil {
+		c.authors = c.Data.Authors()
+	}
+
+	author, found := c.authors.Find(name)
+	if !found {
+		return rejected("That author is not in your library!", WarnStatus)
+	}
+	if slices.Contains(game.AuthorGuesses, author.GRID) {
+		return rejected("You already tried that guess!", WarnStatus)
+	}
+
+	game.AuthorGuesses = append(game.AuthorGuesses, author.GRID)
+	return c.afterGuess(game.Book.Book.Author)
+}
+
+func (c *Controller) afterGuess(answer string) Result {
+	game := c.Game
+	result := Result{Events: []Event{GuessedEvent}}
+
+	if game.Won() {
+		attempts := game.Attempts()
+		s := ""
+		if attempts > 1 {
+			s = "s"
+		}
+		result.Message = fmt.Sprintf("Correct! You got it in %d attempt%s", attempts, s)
+		result.Status = SuccessStatus
+		return c.complete(result)
+	} else if game.Completed() {
+		result.Message = fmt.Sprintf("Failed! The answer was %s", answer)
+		result.Status = ErrorStatus
+		return c.complete(result)
+	}
+
+	result.Message = fmt.Sprintf("Nope! Try again (%d attempts remaining)", game.AttemptsLeft())
+	result.Status = ErrorStatus
+	if game.RevealNextQuote(*c.Data) {
+		result.Message += "\nAnother quote has been revealed"
+		result.Events = append(result.Events, QuoteRevealedEvent)
+	}
+	return result
+}
+
+func (c *Controller) complete(result Result) Result {
+	c.Data.RecordPlayed(c.Game)
+	result.Events = append(result.Events, CompletedEvent)
+	return result
+}
+
+func (c *Controller) UseHint(hint Hint) Result {
+	if status, ok := c.Status(); !ok {
+		return status
+	}
+	if hint == UnredactHint && !c.Game.IsRedacted() {
+		return rejected("The quote isn't hiding anything!", WarnStatus)
+	}
+	if !c.Game.UseHint(hint) {
+		return rejected("You already used that hint!", WarnStatus)
+	}
+	return Result{Events: []Event{HintUsedEvent}}
+}
+
+// Swaps the quote for another before the game has started.
+// Practice games pick with rng, daily games pick again for their day.
+func (c *Controller) Skip(rng *rand.Rand) (Result, error) {
+	game := c.Game
+	if game.Started() {
+		return Result{}, fmt.Errorf("Trying to skip after the game already started")
+	}
+
+	// Mark quote as seen so it won't appear again
+	if !game.Practice {
+		c.Data.Player.SeeQuote(game.QuoteID)
+	}
+
+	result := Result{
+		Events:  []Event{SkippedEvent},
+		Message: fmt.Sprintf("Skipped! The answer was \"%s\"", game.Book.Book.CleanTitle()),
+		Status:  ErrorStatus,
+	}
+
+	var quoteId QuoteId
+	var err error
+	if game.Practice {
+		quoteId, err = c.Data.PickPracticeQuote(game.Mode, rng)
+	} else {
+		quoteId, err = c.Data.PickDailyQuote(game.Day, game.Mode)
+	}
+	if err != nil {
+		return result, fmt.Errorf("Failed to pick quote when skipping:\n%v", err)
+	}
+	game.QuoteID = quoteId
+	game.RevealedQuoteIDs = nil
+	game.StartedAt = time.Now()
+	if err := game.Init(*c.Data); err != nil {
+		return result, err
+	}
+	return result, nil
+}
@@ -0,0 +1,186 @@
+package shared
+
+import (
+	"maps"
+	"math/rand"
+	"slices"
+	"testing"
+	"time"
+)
+
+func startGame(t *testing.T, data *SaveData, mode GameMode) *Controller {
+	t.Helper()
+	game, err := data.StartDailyGame(testDay, mode)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return NewController(data, game)
+}
+
+// Books that aren't the answer, in the same order every run
+func wrongBooks(c *Controller) []BookId {
+	bookIds := slices.Sorted(maps.Keys(c.Data.Books))
+	return slices.DeleteFunc(bookIds, func(bookId BookId) bool { return bookId == c.Game.BookId })
+}
+
+func addDays(t *testing.T, day string, days int) string {
+	t.Helper()
+	date, err := time.Parse(DayLayout, day)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return date.AddDate(0, 0, days).Format(DayLayout)
+}
+
+func TestCorrectGuessWins(t *testing.T) {
+	data := newTestSave(10, 2)
+	c := startGame(t, &data, ClassicMode)
+
+	result := c.GuessBook(c.Game.BookId)
+	if !result.Has(CompletedEvent) || !c.Game.Won() || result.Status != SuccessStatus {
+		t.Fatalf("Expected a win, got %+v", result)
+	}
+	if _, ok := c.Status(); ok {
+		t.Fatal("Finished game should refuse more guesses")
+	}
+}
+
+func TestRunningOutOfGuessesLoses(t *testing.T) {
+	data := newTestSave(10, 2)
+	c := startGame(t, &data, ClassicMode)
+
+	var result Result
+	for _, bookId := range wrongBooks(c)[:MaxGuesses] {
+		result = c.GuessBook(bookId)
+	}
+	if !result.Has(CompletedEvent) || c.Game.Won() || result.Status != ErrorStatus {
+		t.Fatalf("Expected a loss, got %+v", result)
+	}
+	if result := c.GuessBook(c.Game.BookId); !result.Has(RejectedEvent) {
+		t.Fatalf("Guess after losing should be rejected, got %+v", result)
+	}
+}
+
+func TestDuplicateGuessIsRejected(t *testing.T) {
+	data := newTestSave(10, 2)
+	c := startGame(t, &data, ClassicMode)
+
+	wrong := wrongBooks(c)[0]
+	c.GuessBook(wrong)
+	result := c.GuessBook(wrong)
+	if !result.Has(RejectedEvent) || result.Changed() {
+		t.Fatalf("Expected the repeat to be rejected, got %+v", result)
+	}
+	if attempts := c.Game.Attempts(); attempts != 1 {
+		t.Fatalf("Expected 1 attempt, got %d", attempts)
+	}
+}
+
+func TestSkip(t *testing.T) {
+	data := newTestSave(10, 2)
+	c := startGame(t, &data, ClassicMode)
+
+	skipped := c.Game.QuoteID
+	result, err := c.Skip(rand.New(rand.NewSource(1)))
+	if err != nil || !result.Has(SkippedEvent) {
+		t.Fatalf("Expected the skip to work, got %+v, %v", result, err)
+	}
+	if c.Game.QuoteID == skipped || !slices.Contains(data.Player.SeenQuotes, skipped) {
+		t.Fatal("Skipped quote should be replaced and marked as seen")
+	}
+
+	c.GuessBook(wrongBooks(c)[0])
+	if _, err := c.Skip(rand.New(rand.NewSource(1))); err == nil {
+		t.Fatal("Skip should be refused after a guess")
+	}
+}
+
+func TestHintRevealsWords(t *testing.T) {
+	data := newTestSave(10, 2)
+	c := startGame(t, &data, ClassicMode)
+
+	if !c.Game.IsRedacted() || slices.ContainsFunc(c.Game.QuoteTexts(), func(text string) bool {
+		return text == c.Game.Quote.Text
+	}) {
+		t.Fatalf("Expected the character's name to be hidden, got %q", c.Game.QuoteTexts())
+	}
+	if result := c.UseHint(UnredactHint); !result.Has(HintUsedEvent) {
+		t.Fatalf("Expected the hint to be used, got %+v", result)
+	}
+	if texts := c.Game.QuoteTexts(); texts[0] != c.Game.Quote.Text {
+		t.Fatalf("Expected the full quote after the hint, got %q", texts[0])
+	}
+	if result := c.UseHint(UnredactHint); !result.Has(RejectedEvent) {
+		t.Fatalf("Using the hint twice should be rejected, got %+v", result)
+	}
+}
+
+func TestMultiQuoteReveal(t *testing.T) {
+	data := newTestSave(10, 3)
+	c := startGame(t, &data, MultiQuoteMode)
+
+	if len(c.Game.RevealedQuoteIDs) != 1 || c.Game.RevealedQuoteIDs[0] != c.Game.QuoteID {
+		t.Fatalf("Expected the picked quote to be shown first, got %v", c.Game.RevealedQuoteIDs)
+	}
+	result := c.GuessBook(wrongBooks(c)[0])
+	if !result.Has(QuoteRevealedEvent) || len(c.Game.Quotes) != 2 {
+		t.Fatalf("Expected another quote after a wrong guess, got %+v", result)
+	}
+	for _, quote := range c.Game.Quotes {
+		if quote.BookId != c.Game.BookId {
+			t.Fatal("Revealed a quote from another book")
+		}
+	}
+}
+
+func TestAuthorGuesses(t *testing.T) {
+	data := newTestSave(10, 2)
+	c := startGame(t, &data, AuthorMode)
+
+	if result := c.GuessAuthor("Nobody"); !result.Has(RejectedEvent) {
+		t.Fatalf("Unknown author should be rejected, got %+v", result)
+	}
+	for _, author := range data.Authors() {
+		if author.GRID == c.Game.Book.Book.AuthorGRID {
+			continue
+		}
+		if result := c.GuessAuthor(author.Name); !result.Has(GuessedEvent) || c.Game.Won() {
+			t.Fatalf("Expected a wrong guess, got %+v", result)
+		}
+		break
+	}
+	if result := c.GuessAuthor(c.Game.Book.Book.Author); !result.Has(CompletedEvent) || !c.Game.Won() {
+		t.Fatalf("Expected a win, got %+v", result)
+	}
+}
+
+func TestRecordPlayedAndCooldown(t *testing.T) {
+	data := newTestSave(10, 2)
+	data.Player.BookCooldownDays = 5
+	c := startGame(t, &data, ClassicMode)
+	bookId := c.Game.BookId
+
+	c.GuessBook(bookId)
+	if !slices.Contains(data.Player.SeenQuotes, c.Game.QuoteID) {
+		t.Fatal("Finished game's quote should be marked as seen")
+	}
+	if data.RecordPlayed(&Game{Practice: true, QuoteID: 1, Guesses: []BookId{1, 2, 3, 4, 5}}) {
+		t.Fatal("Practice games shouldn't be recorded")
+	}
+
+	if !data.booksOnCooldown(addDays(t, testDay, 4))[bookId] {
+		t.Fatal("Book should sit out within the cooldown")
+	}
+	if data.booksOnCooldown(addDays(t, testDay, 5))[bookId] {
+		t.Fatal("Book should be back once the cooldown is over")
+	}
+	for days := 1; days < 5; days++ {
+		quoteId, err := data.PickDailyQuote(addDays(t, testDay, days), ClassicMode)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if data.Quotes[quoteId].BookId == bookId {
+			t.Fatalf("Picked the same book %d days later", days)
+		}
+	}
+}
@@ -0,0 +1,81 @@
+package shared
+
+import (
+	"fmt"
+	"slices"
+)
+
+const (
+	DefaultBookCooldownDays = 30
+	MaxBookCooldownDays     = 365
+)
+
+// Days before a book can be picked again after it appeared, a negative setting turns it off
+func (p Player) BookCooldown() int64 {
+	if p.BookCooldownDays < 0 {
+		return 0
+	} else if p.BookCooldownDays == 0 {
+		return DefaultBookCooldownDays
+	}
+	return int64(p.BookCooldownDays)
+}
+
+// Whether a BookCooldownDays sent by a client is allowed, -1 (off) and 0 (default) included
+func ValidBookCooldownDays(days int) bool {
+	return days >= -1 && days <= MaxBookCooldownDays
+}
+
+// Sets how many days a book sits out, 0 turns the cooldown off
+func (p *Player) SetBookCooldown(days int) error {
+	if days < 0 || days > MaxBookCooldownDays {
+		return fmt.Errorf("Book cooldown has to be between 0 and %d days", MaxBookCooldownDays)
+	}
+	if days == 0 {
+		days = -1
+	}
+	p.BookCooldownDays = days
+	return nil
+}
+
+// Books that appeared in a game too close to day to be picked for it
+func (s SaveData) booksOnCooldown(day string) map[BookId]bool {
+	onCooldown := make(map[BookId]bool)
+	cooldown := s.Player.BookCooldown()
+	dayNumber, err := DayNumber(day)
+	if cooldown <= 0 || err != nil {
+		return onCooldown
+	}
+
+	for _, game := range s.Player.Games {
+		gameDay, err := DayNumber(game.Day)
+		if err != nil {
+			continue
+		}
+		if distance := max(dayNumber-gameDay, gameDay-dayNumber); distance >= cooldown {
+			continue
+		}
+		if quote, found := s.Quotes[game.QuoteID]; found {
+			onCooldown[quote.BookId] = true
+		}
+	}
+	return onCooldown
+}
+
+func (p *Player) SeeQuote(quoteId QuoteId) {
+	if quoteId != NilID && !slices.Contains(p.SeenQuotes, quoteId) {
+		p.SeenQuotes = append(p.SeenQuotes, quoteId)
+	}
+}
+
+// Marks every quote shown in a finished game as seen so the picker won't bring it back.
+// Practice games leave SeenQuotes alone. Returns whether the game was recorded.
+func (s *SaveData) RecordPlayed(game *Game) bool {
+	if game.Practice || !game.Completed() {
+		return false
+	}
+	s.Player.SeeQuote(game.QuoteID)
+	for _, quoteId := range game.RevealedQuoteIDs {
+		s.Player.SeeQuote(quoteId)
+	}
+	return true
+}
@@ -0,0 +1,21 @@
+package shared
+
+import "testing"
+
+func TestSetBookCooldown(t *testing.T) {
+	var player Player
+	if player.BookCooldown() != DefaultBookCooldownDays {
+		t.Fatalf("Expected the default cooldown, got %d", player.BookCooldown())
+	}
+	if err := player.SetBookCooldown(0); err != nil || player.BookCooldown() != 0 {
+		t.Fatalf("Expected 0 to turn the cooldown off, got %d, %v", player.BookCooldown(), err)
+	}
+	if !ValidBookCooldownDays(player.BookCooldownDays) {
+		t.Fatal("Turned off cooldown should pass the server's check")
+	}
+	for _, days := range []int{-1, MaxBookCooldownDays + 1} {
+		if err := player.SetBookCooldown(days); err == nil {
+			t.Fatalf("Expected %d days to be refused", days)
+		}
+	}
+}
@@ -0,0 +1,79 @@
+package shared
+
+import (
+	"fmt"
+	"time"
+	_ "time/tzdata" // The browser and slim containers don't ship a zoneinfo database
+)
+
+// Games are keyed by the calendar date in the player's timezone
+const DayLayout = "2006-01-02"
+
+func ValidTimezone(name string) bool {
+	if name == "" {
+		return false
+	}
+	_, err := time.LoadLocation(name)
+	return err == nil
+}
+
+func (p Player) Location() *time.Location {
+	if p.Timezone != "" {
+		if loc, err := time.LoadLocation(p.Timezone); err == nil {
+			return loc
+		}
+	}
+	return time.UTC
+}
+
+func (p Player) Day(t time.Time) string {
+	return t.In(p.Location()).Format(DayLayout)
+}
+
+func (p Player) Today() string {
+	return p.Day(time.Now())
+}
+
+// Days since the unix epoch for a DayLayout date
+func DayNumber(day string) (int64, error) {
+	t, err := time.Parse(DayLayout, day)
+	if err != nil {
+		return 0, fmt.Errorf("Invalid day '%s': %v", day, err)
+	}
+	return t.Unix() / int64(24*time.Hour/time.Second), nil
+}
+
+func (p *Player) DailyGame(day string, mode GameMode) *Game {
+	for i := len(p.Games) - 1; i >= 0; i-- {
+		game := &p.Games[i]
+		if game.Mode == mode && game.Day == day {
+			return game
+		}
+	}
+	return nil
+}
+
+// The player's game for day, picking its quote the first time it's played.
+// Days before today are archive games.
+func (s *SaveData) StartDailyGame(day string, mode GameMode) (*Game, error) {
+	if game := s.Player.DailyGame(day, mode); game != nil {
+		return game, game.Init(*s)
+	}
+
+	quoteId, err := s.PickDailyQuote(day, mode)
+	if err != nil {
+		return nil, fmt.Errorf("Failed to pick quote for %s when making new game:\n%v", day, err)
+	}
+
+	player := &s.Player
+	player.Games = append(player.Games, Game{
+		Mode:      mode,
+		QuoteID:   quoteId,
+		Day:       day,
+		StartedAt: time.Now(),
+		Guesses:   make([]BookId, 0),
+		Archive:   day < player.Today(),
+	})
+	game := &player.Games[len(player.Games)-1]
+	return game, game.Init(*s)
+}
@@ -0,0 +1,55 @@
+package shared
+
+import "slices"
+
+// Identifies the work a book is an edition of, falling back to the edition itself
+func (b Book) WorkKey() string {
+	if b.WorkGRID != "" {
+		return "work/" + b.WorkGRID
+	}
+	return "book/" + b.BookGRID
+}
+
+// Goodreads ids of every edition this book stands for
+func (b Book) Editions() []string {
+	if len(b.EditionGRIDs) == 0 {
+		return []string{b.BookGRID}
+	}
+	return b.EditionGRIDs
+}
+
+// Combines two editions of the same work into one book. The more popular edition's
+// details are kept, while the player's ratings and read dates from both are combined.
+func MergeEditions(a UserBook, b UserBook) UserBook {
+	merged := a
+	if b.Book.RatingCount > a.Book.RatingCount {
+		merged.Book = b.Book
+	}
+
+	merged.Book.EditionGRIDs = union(a.Book.Editions(), b.Book.Editions())
+	merged.Book.Characters = union(a.Book.Characters, b.Book.Characters)
+
+	merged.UserData.Stars = max(a.UserData.Stars, b.UserData.Stars)
+	merged.UserData.DatesRead = union(a.UserData.DatesRead, b.UserData.DatesRead)
+	merged.UserData.DateAdded = earliestDate(a.UserData.DateAdded, b.UserData.DateAdded)
+	return merged
+}
+
+func union(a []string, b []string) []string {
+	result := make([]string, 0, len(a)+len(b))
+	for _, s := range slices.Concat(a, b) {
+		if !slices.Contains(result, s) {
+			result = append(result, s)
+		}
+	}
+	return result
+}
+
+func earliestDate(a string, b string) string {
+	aDate, aOk := parseGoodreadsDate(a)
+	bDate, bOk := parseGoodreadsDate(b)
+	if !aOk || (bOk && bDate.Before(aDate)) {
+		return b
+	}
+	return a
+}
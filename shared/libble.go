@@ -1,7 +1,10 @@
 package shared
 
 import (
+	"cmp"
 	"fmt"
+	"hash/fnv"
+	"maps"
 	"math/rand"
 	"slices"
 	"strings"
@@ -18,9 +21,18 @@ const NilID = 0
 type Player struct {
 	ID       DBID   `json:"libble_id"`
 	UserGRID string `json:"user_gr_id"`
+	Timezone string `json:"timezone"` // IANA name, decides when the daily game rolls over
+	// Proves a client owns this save. Saves from before it existed move to a new save to get one.
+	Token string `json:"token,omitempty"`
 
 	SeenQuotes []QuoteId `json:"seen_quote_ids"`
 	Games      []Game    `json:"games"`
+
+	// How many days a book sits out after appearing, 0 uses DefaultBookCooldownDays
+	BookCooldownDays int `json:"book_cooldown_days,omitempty"`
+
+	// Kept apart from Games so they don't count toward daily stats
+	PracticeGames []Game `json:"practice_games"`
 }
 
 type SaveData struct {
@@ -30,16 +42,22 @@ type SaveData struct {
 	Quotes map[QuoteId]Quote   `json:"quotes"`
 }
 
-func (s SaveData) FindBookId(query string) BookId {
-	query = strings.ToLower(strings.TrimSpace(query))
+// Upgrades data saved by older versions
+func (s *SaveData) Migrate() {
+	for _, games := range [][]Game{s.Player.Games, s.Player.PracticeGames} {
+		for i := range games {
+			if games[i].Day == "" && !games[i].StartedAt.IsZero() {
+				// Older versions split days by UTC, so keep the day they were played under
+				games[i].Day = games[i].StartedAt.UTC().Format(DayLayout)
+			}
+		}
+	}
 	for bookId, book := range s.Books {
-		target := book.Book.CleanTitle()
-		target = strings.ToLower(strings.TrimSpace(target))
-		if target == query {
-			return bookId
+		if book.Book.BaseTitle == "" {
+			book.Book.ParseTitle()
+			s.Books[bookId] = book
 		}
 	}
-	return NilID
 }
 
 func IsStaticSaveDataField(jsonFieldName string) bool {
@@ -65,14 +83,60 @@ type UserBookData struct {
 
 const MaxGuesses = 5
 
-type Game struct {
-	QuoteID QuoteId   `json:"quote_id"`
-	Date    time.Time `json:"date_started"`
-	Guesses []BookId  `json:"guesses"`
+type GameMode string
 
-	Quote  Quote
-	BookId BookId
-	Book   UserBook
+const (
+	ClassicMode    GameMode = "" // Empty so games saved before modes existed load as classic
+	MultiQuoteMode GameMode = "multi_quote"
+	AuthorMode     GameMode = "author"
+)
+
+var GameModes = []GameMode{ClassicMode, MultiQuoteMode, AuthorMode}
+
+func (m GameMode) String() string {
+	switch m {
+	case ClassicMode:
+		return "Classic"
+	case MultiQuoteMode:
+		return "Multi-Quote"
+	case AuthorMode:
+		return "Author"
+	}
+	return string(m)
+}
+
+// Offsets the daily seed so each mode gets its own quote
+func (m GameMode) seedOffset() int64 {
+	if m == ClassicMode {
+		return 0
+	}
+	hash := fnv.New32a()
+	hash.Write([]byte(m))
+	return int64(hash.Sum32())
+}
+
+type Game struct {
+	Mode      GameMode  `json:"mode"`
+	QuoteID   QuoteId   `json:"quote_id"`
+	Day       string    `json:"day"` // DayLayout in the player's timezone
+	StartedAt time.Time `json:"date_started"`
+	Guesses   []BookId  `json:"guesses"`
+	Hints     []Hint    `json:"hints"`
+
+	Practice bool `json:"practice,omitempty"`
+	Archive  bool `json:"archive,omitempty"` // Played from the archive after its day was over
+
+	// Only used by AuthorMode, guessed authors' AuthorGRIDs
+	AuthorGuesses []string `json:"author_guesses"`
+
+	// Only used by MultiQuoteMode, in the order they were revealed
+	RevealedQuoteIDs []QuoteId `json:"revealed_quote_ids"`
+
+	// Filled in by Init
+	Quote  Quote    `json:"-"`
+	Quotes []Quote  `json:"-"`
+	BookId BookId   `json:"-"`
+	Book   UserBook `json:"-"`
 }
 
 func (g *Game) Init(data SaveData) error {
@@ -90,36 +154,126 @@ func (g *Game) Init(data SaveData) error {
 		return fmt.Errorf("Daily Quote's book Id was not found in books map")
 	}
 	g.Book = book
+
+	g.Quotes = []Quote{quote}
+	if g.Mode == MultiQuoteMode {
+		// Starts from the picked quote so the one marked as seen is the one that was shown
+		if len(g.RevealedQuoteIDs) == 0 {
+			g.RevealedQuoteIDs = []QuoteId{g.QuoteID}
+		}
+		g.Quotes = make([]Quote, 0, len(g.RevealedQuoteIDs))
+		for _, quoteId := range g.RevealedQuoteIDs {
+			if revealed, found := data.Quotes[quoteId]; found {
+				g.Quotes = append(g.Quotes, revealed)
+			}
+		}
+	}
 	return nil
 }
 
+// Reveals the least liked quote from the same book that hasn't been shown yet, returns false if there are none left
+func (g *Game) RevealNextQuote(data SaveData) bool {
+	if g.Mode != MultiQuoteMode {
+		return false
+	}
+	for _, quoteId := range data.BookQuotes(g.BookId) {
+		if slices.Contains(g.RevealedQuoteIDs, quoteId) {
+			continue
+		}
+		g.RevealedQuoteIDs = append(g.RevealedQuoteIDs, quoteId)
+		g.Quotes = append(g.Quotes, data.Quotes[quoteId])
+		return true
+	}
+	return false
+}
+
 func (g Game) Started() bool {
-	return g.Attempts() > 0 // NOTE: add hints here later
+	return g.Attempts() > 0 || len(g.Hints) > 0
 }
 
 func (g Game) Attempts() int {
+	if g.Mode == AuthorMode {
+		return len(g.AuthorGuesses)
+	}
 	return len(g.Guesses)
 }
 func (g Game) AttemptsLeft() int {
-	return max(MaxGuesses-len(g.Guesses), 0)
+	return max(MaxGuesses-g.Attempts(), 0)
 }
 func (g Game) Completed() bool {
 	return g.AttemptsLeft() <= 0 || g.Won()
 }
 func (g Game) Won() bool {
+	if g.Mode == AuthorMode {
+		if len(g.AuthorGuesses) <= 0 {
+			return false
+		}
+		return g.AuthorGuesses[len(g.AuthorGuesses)-1] == g.Book.Book.AuthorGRID
+	}
 	if len(g.Guesses) <= 0 {
 		return false
 	}
 	return g.Guesses[len(g.Guesses)-1] == g.BookId
 }
 
+type Hint string
+
+const (
+	UnredactHint Hint = "unredact"
+)
+
+func (g Game) UsedHint(hint Hint) bool {
+	return slices.Contains(g.Hints, hint)
+}
+
+// Returns false if the hint was already used
+func (g *Game) UseHint(hint Hint) bool {
+	if g.UsedHint(hint) {
+		return false
+	}
+	g.Hints = append(g.Hints, hint)
+	return true
+}
+
+func (g Game) IsRedacted() bool {
+	for i, text := range g.QuoteTexts() {
+		if text != g.Quotes[i].Text {
+			return true
+		}
+	}
+	return false
+}
+
+// Every revealed quote as it should be shown, names are hidden until revealed or the game is over
+func (g Game) QuoteTexts() []string {
+	texts := make([]string, len(g.Quotes))
+	for i, quote := range g.Quotes {
+		if g.Completed() || g.UsedHint(UnredactHint) {
+			texts[i] = quote.Text
+		} else {
+			texts[i] = quote.Redacted(g.Book.Book)
+		}
+	}
+	return texts
+}
+
 type Book struct {
 	BookGRID    string  `json:"book_gr_id"`
+	WorkGRID    string  `json:"work_gr_id,omitempty"` // Shared by every edition of the book
 	Title       string  `json:"title"`
 	Author      string  `json:"author"`
 	AuthorGRID  string  `json:"author_gr_id"`
 	AvgRating   float32 `json:"avg_rating"`
 	RatingCount uint    `json:"rating_count"`
+
+	// Title split into its parts, see ParseTitle
+	BaseTitle    string `json:"base_title"`
+	Series       string `json:"series,omitempty"`
+	SeriesNumber string `json:"series_number,omitempty"`
+
+	Characters []string `json:"characters"`
+	// Every edition in the player's library merged into this book, see MergeEditions
+	EditionGRIDs []string `json:"edition_gr_ids,omitempty"`
 }
 
 func (b Book) CleanTitle() string {
@@ -151,71 +305,126 @@ func (b UserBookData) IsRead() bool {
 	return false
 }
 
-func (s SaveData) PickDailyQuote() (QuoteId, error) {
+// Quotes for a book ordered from least to most liked
+func (s SaveData) BookQuotes(bookId BookId) []QuoteId {
+	quoteIds := make([]QuoteId, 0, 8)
+	for quoteId, quote := range s.Quotes {
+		if quote.BookId == bookId {
+			quoteIds = append(quoteIds, quoteId)
+		}
+	}
+	slices.SortFunc(quoteIds, func(a QuoteId, b QuoteId) int {
+		quoteA, quoteB := s.Quotes[a], s.Quotes[b]
+		if quoteA.Likes != quoteB.Likes {
+			return cmp.Compare(quoteA.Likes, quoteB.Likes)
+		}
+		return strings.Compare(quoteA.QuoteGRID, quoteB.QuoteGRID)
+	})
+	return quoteIds
+}
+
+func (s SaveData) PickDailyQuote(day string, mode GameMode) (QuoteId, error) {
 	var quoteId QuoteId
 	quoteCount := len(s.Quotes)
 	if quoteCount <= 0 {
 		return quoteId, fmt.Errorf("User has no quotes")
 	}
 
-	now := time.Now().UTC()
-	seed := now.Year() + now.YearDay()
+	dayNumber, err := DayNumber(day)
+	if err != nil {
+		return quoteId, err
+	}
+	seed := dayNumber + mode.seedOffset()
 	fmt.Printf("Random Seed: %d\n", seed)
-	rng := rand.New(rand.NewSource(int64(seed)))
+	rng := rand.New(rand.NewSource(seed))
+
+	// Recently played books sit out unless there's nothing else left
+	onCooldown := s.booksOnCooldown(day)
+	noCooldown := map[BookId]bool{}
+
+	if mode == AuthorMode {
+		for _, skipBooks := range []map[BookId]bool{onCooldown, noCooldown} {
+			if quoteId, found := s.pickAuthorQuote(rng, skipBooks); found {
+				return quoteId, nil
+			}
+		}
+	}
+
+	// Multi-quote games need more than one quote to reveal
+	bookQuoteCounts := make(map[BookId]int)
+	if mode == MultiQuoteMode {
+		for _, quote := range s.Quotes {
+			bookQuoteCounts[quote.BookId] += 1
+		}
+	}
 
 	type weightedQuote struct {
 		quote QuoteId
 		tries uint8
 	}
 
+	// Sorted since map order changes every run, and the same day has to give the same pick everywhere
+	quoteIds := slices.Sorted(maps.Keys(s.Quotes))
 	quotes := make([]weightedQuote, quoteCount)
-	triedCount := 0
-	collisions := 0
-
-	quoteIndex := 0
-	for id, _ := range s.Quotes {
-		quotes[quoteIndex].quote = id
-		quoteIndex++
+	for i, id := range quoteIds {
+		quotes[i].quote = id
 	}
 
-	for triedCount < quoteCount && collisions < quoteCount*2 {
-		quoteIndex := rng.Intn(quoteCount)
+	pick := func(skipBooks map[BookId]bool) (QuoteId, bool) {
+		for i := range quotes {
+			quotes[i].tries = 0
+		}
+		triedCount := 0
+		collisions := 0
+
+		for triedCount < quoteCount && collisions < quoteCount*2 {
+			quoteIndex := rng.Intn(quoteCount)
+
+			quoteId := quotes[quoteIndex].quote
+			tries := quotes[quoteIndex].tries
+			if tries > 0 {
+				collisions += 1
+				if tries == 100 {
+					panic("Too many tries")
+				}
+				quotes[quoteIndex].tries += 1
+				continue
+			}
 
-		quoteId := quotes[quoteIndex].quote
-		tries := quotes[quoteIndex].tries
-		if tries > 0 {
-			collisions += 1
-			if tries == 100 {
-				panic("Too many tries")
+			triedCount += 1
+			if slices.Contains(s.Player.SeenQuotes, quoteId) {
+				continue
 			}
-			quotes[quoteIndex].tries += 1
-			continue
-		}
 
-		triedCount += 1
-		if slices.Contains(s.Player.SeenQuotes, quoteId) {
-			continue
+			// Check if book is read
+			quote, found := s.Quotes[quoteId]
+			if !found {
+				panic("Couldn't get quote back")
+			}
+			book, found := s.Books[quote.BookId]
+			if !found {
+				fmt.Printf("Couldn't find book with id %d for quote %d\n", quote.BookId, quoteId)
+				continue
+			}
+			if !book.UserData.IsRead() || skipBooks[quote.BookId] {
+				continue
+			}
+			if mode == MultiQuoteMode && bookQuoteCounts[quote.BookId] < 2 {
+				continue
+			}
+			return quoteId, true
 		}
+		return NilID, false
+	}
 
-		// Check if book is read
-		quote, found := s.Quotes[quoteId]
-		if !found {
-			panic("Couldn't get quote back")
-		}
-		book, found := s.Books[quote.BookId]
-		if !found {
-			fmt.Printf("Couldn't find book with id %d for quote %d\n", quote.BookId, quoteId)
-			continue
-		}
-		if !book.UserData.IsRead() {
-			continue
+	for _, skipBooks := range []map[BookId]bool{onCooldown, noCooldown} {
+		if quoteId, found := pick(skipBooks); found {
+			return quoteId, nil
 		}
-		return quoteId, nil
 	}
 
 	fmt.Printf("Warning: Recycling quote for %s\n", s.Player.UserGRID)
-	quoteIndex = rng.Intn(quoteCount)
-	return quotes[quoteIndex].quote, nil
+	return quotes[rng.Intn(quoteCount)].quote, nil
 }
 
 // Returns index from `availableQuotes`
@@ -0,0 +1,75 @@
+package shared
+
+import (
+	"fmt"
+	"testing"
+	"time"
+)
+
+const testDay = "2025-03-14"
+
+// A library of read books by a few authors, each with a few quotes
+func newTestSave(bookCount int, quotesPerBook int) SaveData {
+	data := SaveData{
+		Player: Player{ID: 1, UserGRID: "1", Timezone: "UTC"},
+		Books:  make(map[BookId]UserBook),
+		Quotes: make(map[QuoteId]Quote),
+	}
+	for b := range bookCount {
+		bookId := BookId(1000 + b)
+		book := Book{
+			BookGRID:   fmt.Sprint(bookId),
+			Title:      fmt.Sprintf("Book %d", b),
+			Author:     fmt.Sprintf("Author %d", b%3),
+			AuthorGRID: fmt.Sprint(b % 3),
+			Characters: []string{"Alice Marsh"},
+		}
+		book.ParseTitle()
+		data.Books[bookId] = UserBook{Book: book, UserData: UserBookData{Stars: 4}}
+
+		for q := range quotesPerBook {
+			quoteId := QuoteId(100000 + b*100 + q)
+			data.Quotes[quoteId] = Quote{
+				QuoteGRID: fmt.Sprint(quoteId),
+				Likes:     uint(q),
+				Text:      fmt.Sprintf("Alice said quote %d", q),
+				BookId:    bookId,
+				BookGRID:  book.BookGRID,
+			}
+		}
+	}
+	return data
+}
+
+func TestPickDailyQuoteIsDeterministic(t *testing.T) {
+	data := newTestSave(20, 3)
+	for _, mode := range GameModes {
+		first, err := data.PickDailyQuote(testDay, mode)
+		if err != nil {
+			t.Fatalf("%s: %v", mode, err)
+		}
+		// Map order changes between iterations, so a few tries catch picks that depend on it
+		for range 10 {
+			again, err := data.PickDailyQuote(testDay, mode)
+			if err != nil {
+				t.Fatalf("%s: %v", mode, err)
+			}
+			if again != first {
+				t.Fatalf("%s: picked %d then %d for the same day", mode, first, again)
+			}
+		}
+	}
+}
+
+func TestMigrateKeepsUTCDay(t *testing.T) {
+	data := newTestSave(1, 1)
+	data.Player.Timezone = "America/Los_Angeles"
+	// An evening game in Los Angeles, which is already the next day in UTC
+	startedAt := time.Date(2025, 3, 14, 4, 0, 0, 0, time.UTC)
+	data.Player.Games = []Game{{QuoteID: 100000, StartedAt: startedAt}}
+
+	data.Migrate()
+	if day := data.Player.Games[0].Day; day != testDay {
+		t.Fatalf("Expected the legacy game to keep its UTC day %s, got %s", testDay, day)
+	}
+}
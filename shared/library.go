@@ -0,0 +1,126 @@
+package shared
+
+import "math/rand/v2"
+
+// Books and quotes with the ids they're saved under, what the server sends back after a refresh
+type LibraryUpdate struct {
+	Books     map[BookId]UserBook `json:"books"`
+	Quotes    map[QuoteId]Quote   `json:"quotes"`
+	NewBooks  int                 `json:"new_books"`
+	NewQuotes int                 `json:"new_quotes"`
+}
+
+// Adds freshly scraped books and quotes to the library. Books and quotes already in it keep
+// their ids so games still point at them, and nothing is ever removed.
+// Editions of the same work end up under one id, see MergeEditions.
+func (s *SaveData) MergeLibrary(books []UserBook, quotes []Quote) (update LibraryUpdate) {
+	if s.Books == nil {
+		s.Books = make(map[BookId]UserBook)
+	}
+	if s.Quotes == nil {
+		s.Quotes = make(map[QuoteId]Quote)
+	}
+
+	idsByGRID := make(map[string]BookId)
+	idsByWork := make(map[string]BookId)
+	for bookId, book := range s.Books {
+		for _, grid := range book.Book.Editions() {
+			idsByGRID[grid] = bookId
+		}
+		idsByWork[book.Book.WorkKey()] = bookId
+	}
+
+	// The first edition seen replaces what was saved, any after it are merged in
+	refreshed := make(map[BookId]bool)
+	for _, book := range books {
+		bookId, found := idsByGRID[book.Book.BookGRID]
+		if !found {
+			bookId, found = idsByWork[book.Book.WorkKey()]
+		}
+
+		if !found {
+			bookId = s.newBookId()
+			update.NewBooks += 1
+		} else if refreshed[bookId] {
+			book = MergeEditions(s.Books[bookId], book)
+		} else {
+			// Book pages aren't scraped every time, so keep what was found before
+			saved := s.Books[bookId].Book
+			if book.Book.WorkGRID == "" {
+				book.Book.WorkGRID = saved.WorkGRID
+			}
+			if len(book.Book.Characters) == 0 {
+				book.Book.Characters = saved.Characters
+			}
+			if editions := union(saved.Editions(), book.Book.Editions()); len(editions) > 1 {
+				book.Book.EditionGRIDs = editions
+			}
+		}
+		s.Books[bookId] = book
+		refreshed[bookId] = true
+		idsByGRID[book.Book.BookGRID] = bookId
+		idsByWork[book.Book.WorkKey()] = bookId
+	}
+
+	// Editions of a work tend to share the same quotes
+	idsByQuoteGRID := make(map[string]QuoteId)
+	for quoteId, quote := range s.Quotes {
+		if quote.QuoteGRID != "" {
+			idsByQuoteGRID[quote.QuoteGRID] = quoteId
+		}
+	}
+	for _, quote := range quotes {
+		if quote.BookGRID != "" {
+			quote.BookId = idsByGRID[quote.BookGRID]
+		}
+		if quoteId, found := idsByQuoteGRID[quote.QuoteGRID]; found && quote.QuoteGRID != "" {
+			s.Quotes[quoteId] = quote
+			continue
+		}
+
+		quoteId := s.newQuoteId()
+		s.Quotes[quoteId] = quote
+		if quote.QuoteGRID != "" {
+			idsByQuoteGRID[quote.QuoteGRID] = quoteId
+		}
+		update.NewQuotes += 1
+	}
+
+	update.Books = s.Books
+	update.Quotes = s.Quotes
+	return update
+}
+
+// Takes the library the server merged, leaving games and everything else alone
+func (s *SaveData) ApplyLibraryUpdate(update LibraryUpdate) {
+	if s.Books == nil {
+		s.Books = make(map[BookId]UserBook)
+	}
+	if s.Quotes == nil {
+		s.Quotes = make(map[QuoteId]Quote)
+	}
+	for bookId, book := range update.Books {
+		s.Books[bookId] = book
+	}
+	for quoteId, quote := range update.Quotes {
+		s.Quotes[quoteId] = quote
+	}
+}
+
+func (s SaveData) newBookId() BookId {
+	for {
+		bookId := BookId(rand.Uint64())
+		if _, exists := s.Books[bookId]; !exists && bookId != NilID {
+			return bookId
+		}
+	}
+}
+
+func (s SaveData) newQuoteId() QuoteId {
+	for {
+		quoteId := QuoteId(rand.Uint64())
+		if _, exists := s.Quotes[quoteId]; !exists && quoteId != NilID {
+			return quoteId
+		}
+	}
+}
@@ -0,0 +1,155 @@
+package shared
+
+import (
+	"cmp"
+	"math"
+	"slices"
+	"strings"
+	"unicode"
+
+	"golang.org/x/text/runes"
+	"golang.org/x/text/transform"
+	"golang.org/x/text/unicode/norm"
+)
+
+const (
+	maxTypos     = 3    // Most edits a guess can be off by and still count
+	maxTypoRatio = 0.25 // ...as a fraction of the title's length
+)
+
+var leadingArticles = []string{"the ", "a ", "an "}
+
+// Lowercase without diacritics, punctuation or a leading article and with whitespace collapsed
+func NormalizeTitle(title string) string {
+	stripDiacritics := transform.Chain(norm.NFD, runes.Remove(runes.In(unicode.Mn)), norm.NFC)
+	if stripped, _, err := transform.String(stripDiacritics, title); err == nil {
+		title = stripped
+	}
+
+	title = strings.Map(func(r rune) rune {
+		switch {
+		case unicode.IsLetter(r) || unicode.IsNumber(r):
+			return unicode.ToLower(r)
+		case r == '\'' || r == '’':
+			return -1 // So "Ender's" and "Enders" match
+		}
+		return ' '
+	}, title)
+	title = strings.Join(strings.Fields(title), " ")
+
+	for _, article := range leadingArticles {
+		if trimmed, found := strings.CutPrefix(title, article); found {
+			return trimmed
+		}
+	}
+	return title
+}
+
+type matchTitle struct {
+	bookId BookId
+	full   string // Without the series suffix
+	short  string // ...and without the subtitle after a colon
+	series string // The whole Goodreads title, for players who type the series too
+}
+
+type TitleMatcher struct {
+	titles []matchTitle
+}
+
+func NewTitleMatcher(books map[BookId]UserBook) TitleMatcher {
+	matcher := TitleMatcher{titles: make([]matchTitle, 0, len(books))}
+	for bookId, book := range books {
+		title := book.Book.ShortTitle()
+		short, _, _ := strings.Cut(title, ":")
+		matcher.titles = append(matcher.titles, matchTitle{
+			bookId: bookId,
+			full:   NormalizeTitle(title),
+			short:  NormalizeTitle(short),
+			series: NormalizeTitle(book.Book.CleanTitle()),
+		})
+	}
+	slices.SortFunc(matcher.titles, func(a matchTitle, b matchTitle) int {
+		if c := strings.Compare(a.full, b.full); c != 0 {
+			return c
+		}
+		return cmp.Compare(a.bookId, b.bookId)
+	})
+	return matcher
+}
+
+// Books the query could mean, closest first. More than one means the player should pick.
+func (m TitleMatcher) Match(query string) []BookId {
+	query = NormalizeTitle(query)
+	if query == "" {
+		return nil
+	}
+
+	exact := make([]BookId, 0, 1)
+	for _, title := range m.titles {
+		if query == title.full || query == title.short || query == title.series {
+			exact = append(exact, title.bookId)
+		}
+	}
+	if len(exact) > 0 {
+		return exact
+	}
+
+	type candidate struct {
+		bookId   BookId
+		distance int
+	}
+	candidates := make([]candidate, 0, 4)
+	for _, title := range m.titles {
+		distance := math.MaxInt
+		for _, target := range []string{title.full, title.short, title.series} {
+			d := LevenshteinDistance(query, target)
+			ratio := float64(d) / float64(max(len([]rune(query)), len([]rune(target))))
+			if d <= maxTypos && ratio <= maxTypoRatio {
+				distance = min(distance, d)
+			}
+		}
+		if distance != math.MaxInt {
+			candidates = append(candidates, candidate{title.bookId, distance})
+		}
+	}
+	if len(candidates) == 0 {
+		return nil
+	}
+
+	// Anything about as close as the best match is ambiguous
+	slices.SortStableFunc(candidates, func(a candidate, b candidate) int {
+		return cmp.Compare(a.distance, b.distance)
+	})
+	closest := candidates[0].distance
+	matches := make([]BookId, 0, len(candidates))
+	for _, c := range candidates {
+		if c.distance <= closest+1 {
+			matches = append(matches, c.bookId)
+		}
+	}
+	return matches
+}
+
+func LevenshteinDistance(s, t string) int {
+	r1, r2 := []rune(s), []rune(t)
+	column := make([]int, 1, 64)
+
+	for y := 1; y <= len(r1); y++ {
+		column = append(column, y)
+	}
+
+	for x := 1; x <= len(r2); x++ {
+		column[0] = x
+
+		for y, lastDiag := 1, x-1; y <= len(r1); y++ {
+			oldDiag := column[y]
+			cost := 0
+			if r1[y-1] != r2[x-1] {
+				cost = 1
+			}
+			column[y] = min(column[y]+1, column[y-1]+1, lastDiag+cost)
+			lastDiag = oldDiag
+		}
+	}
+	return column[len(r1)]
+}
@@ -0,0 +1,65 @@
+package shared
+
+import (
+	"fmt"
+	"math/rand"
+	"slices"
+)
+
+// How many recent practice quotes to avoid repeating
+const practiceLookback = 50
+
+// Picks a random quote for a practice game, doesn't look at or change SeenQuotes
+func (s SaveData) PickPracticeQuote(mode GameMode, rng *rand.Rand) (QuoteId, error) {
+	recent := make([]QuoteId, 0, practiceLookback)
+	practiceGames := s.Player.PracticeGames
+	for i := len(practiceGames) - 1; i >= 0 && len(recent) < practiceLookback; i-- {
+		recent = append(recent, practiceGames[i].QuoteID)
+	}
+
+	bookQuoteCounts := make(map[BookId]int)
+	for _, quote := range s.Quotes {
+		bookQuoteCounts[quote.BookId] += 1
+	}
+
+	candidates := make([]QuoteId, 0, len(s.Quotes))
+	fallback := make([]QuoteId, 0, len(s.Quotes))
+	for quoteId, quote := range s.Quotes {
+		book, found := s.Books[quote.BookId]
+		if !found || !book.UserData.IsRead() {
+			continue
+		}
+		if mode == MultiQuoteMode && bookQuoteCounts[quote.BookId] < 2 {
+			continue
+		}
+		fallback = append(fallback, quoteId)
+		if !slices.Contains(recent, quoteId) {
+			candidates = append(candidates, quoteId)
+		}
+	}
+
+	if len(candidates) == 0 {
+		candidates = fallback
+	}
+	slices.Sort(candidates) // Map order is random, so the rng alone would never repeat a pick
+	if len(candidates) == 0 {
+		return NilID, fmt.Errorf("User has no quotes to practice with")
+	}
+	return candidates[rng.Intn(len(candidates))], nil
+}
+
+// The unfinished practice game for a mode already initialized, if there is one
+func (s *SaveData) CurrentPracticeGame(mode GameMode) *Game {
+	practiceGames := s.Player.PracticeGames
+	for i := len(practiceGames) - 1; i >= 0; i-- {
+		game := &practiceGames[i]
+		if game.Mode != mode {
+			continue
+		}
+		if err := game.Init(*s); err != nil || game.Completed() {
+			return nil
+		}
+		return game
+	}
+	return nil
+}
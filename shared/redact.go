@@ -0,0 +1,82 @@
+package shared
+
+import (
+	"slices"
+	"strings"
+	"unicode"
+)
+
+const (
+	RedactedRune    = '█'
+	minRedactLength = 2
+)
+
+// Words too common to give a book away on their own
+var redactStopWords = map[string]bool{
+	"a": true, "an": true, "and": true, "as": true, "at": true, "by": true,
+	"for": true, "from": true, "in": true, "into": true, "is": true, "it": true,
+	"of": true, "on": true, "or": true, "the": true, "to": true, "with": true,
+	"mr": true, "mrs": true, "ms": true, "dr": true, "sir": true, "lady": true,
+}
+
+func isWordRune(r rune) bool {
+	return unicode.IsLetter(r) || unicode.IsNumber(r)
+}
+
+// Lowercase words from character names and the title that should be hidden in quotes
+func (b Book) RedactWords() []string {
+	words := make([]string, 0, 8)
+	add := func(text string) {
+		for _, word := range strings.FieldsFunc(text, func(r rune) bool { return !isWordRune(r) }) {
+			word = strings.ToLower(word)
+			if len([]rune(word)) < minRedactLength || redactStopWords[word] {
+				continue
+			}
+			if !slices.Contains(words, word) {
+				words = append(words, word)
+			}
+		}
+	}
+	for _, name := range b.Characters {
+		add(name)
+	}
+	add(b.CleanTitle())
+	return words
+}
+
+// Replaces every whole word in `text` found in `words` with RedactedRune
+func Redact(text string, words []string) string {
+	if len(words) == 0 {
+		return text
+	}
+
+	var builder strings.Builder
+	builder.Grow(len(text))
+	word := make([]rune, 0, 16)
+	flush := func() {
+		if len(word) == 0 {
+			return
+		}
+		if slices.Contains(words, strings.ToLower(string(word))) {
+			builder.WriteString(strings.Repeat(string(RedactedRune), len(word)))
+		} else {
+			builder.WriteString(string(word))
+		}
+		word = word[:0]
+	}
+
+	for _, r := range text {
+		if isWordRune(r) {
+			word = append(word, r)
+			continue
+		}
+		flush()
+		builder.WriteRune(r)
+	}
+	flush()
+	return builder.String()
+}
+
+func (q Quote) Redacted(book Book) string {
+	return Redact(q.Text, book.RedactWords())
+}
@@ -0,0 +1,57 @@
+package shared
+
+import (
+	"cmp"
+	"slices"
+
+	"github.com/sahilm/fuzzy"
+)
+
+// Something from a search, found by its label, its detail or both
+type SearchMatch struct {
+	Index int // Into the searched source
+	Score int
+	// Byte indexes of the characters that matched, for highlighting
+	LabelIndexes  []int
+	DetailIndexes []int
+}
+
+// Fuzzy searches labels and details together, best matches first.
+// details has to line up with labels, or be nil to only search labels.
+func Search(query string, labels fuzzy.Source, details fuzzy.Source) []SearchMatch {
+	byIndex := make(map[int]*SearchMatch)
+	matches := make([]*SearchMatch, 0)
+	add := func(match fuzzy.Match, isDetail bool) {
+		found, ok := byIndex[match.Index]
+		if !ok {
+			found = &SearchMatch{Index: match.Index, Score: match.Score}
+			byIndex[match.Index] = found
+			matches = append(matches, found)
+		}
+		found.Score = max(found.Score, match.Score)
+		if isDetail {
+			found.DetailIndexes = match.MatchedIndexes
+		} else {
+			found.LabelIndexes = match.MatchedIndexes
+		}
+	}
+
+	for _, match := range fuzzy.FindFrom(query, labels) {
+		add(match, false)
+	}
+	if details != nil {
+		for _, match := range fuzzy.FindFrom(query, details) {
+			add(match, true)
+		}
+	}
+
+	// Ties keep the source's order so the list doesn't jump around while typing
+	slices.SortStableFunc(matches, func(a, b *SearchMatch) int {
+		return cmp.Or(cmp.Compare(b.Score, a.Score), cmp.Compare(a.Index, b.Index))
+	})
+	results := make([]SearchMatch, len(matches))
+	for i, match := range matches {
+		results[i] = *match
+	}
+	return results
+}
@@ -0,0 +1,82 @@
+package shared
+
+import (
+	"fmt"
+	"strings"
+)
+
+// Share text counts puzzles from the day libble launched
+const LaunchDay = "2025-10-01"
+
+func PuzzleNumber(day string) int {
+	launch, err := DayNumber(LaunchDay)
+	if err != nil {
+		return 0
+	}
+	number, err := DayNumber(day)
+	if err != nil {
+		return 0
+	}
+	return int(number-launch) + 1
+}
+
+func clueSquare(result ClueResult) string {
+	switch result {
+	case ClueMatch:
+		return "🟩"
+	case ClueHigher, ClueLower:
+		return "🟨"
+	}
+	return "⬛"
+}
+
+// Spoiler free summary of a game for sharing, Wordle style
+func (g Game) ShareText(data SaveData) string {
+	var builder strings.Builder
+
+	title := fmt.Sprintf("Libble #%d", PuzzleNumber(g.Day))
+	if g.Practice {
+		title = "Libble Practice"
+	}
+	if g.Mode != ClassicMode {
+		title += " (" + g.Mode.String() + ")"
+	}
+	score := "X"
+	if g.Won() {
+		score = fmt.Sprint(g.Attempts())
+	}
+	fmt.Fprintf(&builder, "📖 %s %s/%d\n", title, score, MaxGuesses)
+
+	if g.Mode == AuthorMode {
+		for _, guess := range g.AuthorGuesses {
+			if guess == g.Book.Book.AuthorGRID {
+				builder.WriteString("🟩\n")
+			} else {
+				builder.WriteString("🟥\n")
+			}
+		}
+	} else {
+		for _, guess := range g.Guesses {
+			if guess == g.BookId {
+				builder.WriteString("🟩\n")
+				continue
+			}
+			builder.WriteString("🟥 ")
+			if book, found := data.Books[guess]; found {
+				for _, clue := range CompareBooks(book, g.Book) {
+					builder.WriteString(clueSquare(clue.Result))
+				}
+			}
+			builder.WriteString("\n")
+		}
+	}
+
+	if hints := len(g.Hints); hints > 0 {
+		s := ""
+		if hints > 1 {
+			s = "s"
+		}
+		fmt.Fprintf(&builder, "💡 %d hint%s used\n", hints, s)
+	}
+	return strings.TrimSpace(builder.String())
+}
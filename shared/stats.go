@@ -0,0 +1,127 @@
+package shared
+
+import (
+	"slices"
+)
+
+type Accuracy struct {
+	Played int `json:"played"`
+	Won    int `json:"won"`
+}
+
+func (a Accuracy) Percent() float64 {
+	if a.Played == 0 {
+		return 0
+	}
+	return float64(a.Won) / float64(a.Played) * 100
+}
+
+type Stats struct {
+	Mode          GameMode `json:"mode"`
+	Played        int      `json:"played"`
+	Won           int      `json:"won"`
+	WinPercent    float64  `json:"win_percent"`
+	CurrentStreak int      `json:"current_streak"`
+	MaxStreak     int      `json:"max_streak"`
+
+	// Archive plays don't count toward anything above
+	ArchivePlayed int `json:"archive_played"`
+	ArchiveWon    int `json:"archive_won"`
+
+	// Wins by how many attempts they took, index 0 is a first guess win
+	GuessDistribution [MaxGuesses]int `json:"guess_distribution"`
+	AverageAttempts   float64         `json:"average_attempts"`
+
+	ByAuthor map[string]Accuracy `json:"by_author"` // Keyed by author name
+	ByYear   map[int]Accuracy    `json:"by_year"`   // Keyed by the year the answer was last read
+}
+
+// Summarizes the player's finished daily games for a mode
+func (s SaveData) Stats(mode GameMode) Stats {
+	stats := Stats{
+		Mode:     mode,
+		ByAuthor: make(map[string]Accuracy),
+		ByYear:   make(map[int]Accuracy),
+	}
+
+	type dayResult struct {
+		day int64
+		won bool
+	}
+	results := make([]dayResult, 0, len(s.Player.Games))
+	totalAttempts := 0
+
+	for _, game := range s.Player.Games {
+		if game.Mode != mode || game.Practice {
+			continue
+		}
+		if err := game.Init(s); err != nil || !game.Completed() {
+			continue
+		}
+		if game.Archive {
+			stats.ArchivePlayed += 1
+			if game.Won() {
+				stats.ArchiveWon += 1
+			}
+			continue
+		}
+		day, err := DayNumber(game.Day)
+		if err != nil {
+			continue
+		}
+
+		won := game.Won()
+		results = append(results, dayResult{day, won})
+		stats.Played += 1
+		totalAttempts += game.Attempts()
+		if won {
+			stats.Won += 1
+			stats.GuessDistribution[min(game.Attempts(), MaxGuesses)-1] += 1
+		}
+
+		addResult := func(accuracy Accuracy) Accuracy {
+			accuracy.Played += 1
+			if won {
+				accuracy.Won += 1
+			}
+			return accuracy
+		}
+		author := game.Book.Book.Author
+		stats.ByAuthor[author] = addResult(stats.ByAuthor[author])
+		if lastRead, found := game.Book.UserData.LastRead(); found {
+			stats.ByYear[lastRead.Year()] = addResult(stats.ByYear[lastRead.Year()])
+		}
+	}
+
+	if stats.Played > 0 {
+		stats.WinPercent = float64(stats.Won) / float64(stats.Played) * 100
+		stats.AverageAttempts = float64(totalAttempts) / float64(stats.Played)
+	}
+
+	// A streak is consecutive days won, a loss or a missed day ends it
+	slices.SortFunc(results, func(a dayResult, b dayResult) int {
+		return int(a.day - b.day)
+	})
+	streak := 0
+	for i, result := range results {
+		switch {
+		case !result.won:
+			streak = 0
+		case i > 0 && results[i-1].won && results[i-1].day == result.day-1:
+			streak += 1
+		default:
+			streak = 1
+		}
+		stats.MaxStreak = max(stats.MaxStreak, streak)
+	}
+
+	// The current streak is only alive if it was extended today or yesterday
+	if today, err := DayNumber(s.Player.Today()); err == nil && len(results) > 0 {
+		last := results[len(results)-1]
+		if last.day >= today-1 {
+			stats.CurrentStreak = streak
+		}
+	}
+
+	return stats
+}
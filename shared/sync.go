@@ -0,0 +1,58 @@
+package shared
+
+import "slices"
+
+// Combines the player a client sent with the one already saved, so clients playing the same
+// save don't wipe out each other's games. Settings come from the client.
+func (s SaveData) MergePlayer(incoming Player) Player {
+	saved := s.Player
+	merged := incoming
+	merged.SeenQuotes = slices.Clone(saved.SeenQuotes)
+	for _, quoteId := range incoming.SeenQuotes {
+		merged.SeeQuote(quoteId)
+	}
+
+	merged.Games = s.mergeGames(saved.Games, incoming.Games, func(g Game) any {
+		return [2]string{g.Day, string(g.Mode)}
+	})
+	// Practice games have no day to match on, but each one starts at its own time
+	merged.PracticeGames = s.mergeGames(saved.PracticeGames, incoming.PracticeGames, func(g Game) any {
+		return g.StartedAt.UnixNano()
+	})
+	return merged
+}
+
+// Games in either list, keeping whichever copy got further when both have the same key
+func (s SaveData) mergeGames(saved []Game, incoming []Game, key func(Game) any) []Game {
+	merged := slices.Clone(saved)
+	indexes := make(map[any]int, len(merged))
+	for i, game := range merged {
+		indexes[key(game)] = i
+	}
+
+	for _, game := range incoming {
+		i, found := indexes[key(game)]
+		if !found {
+			indexes[key(game)] = len(merged)
+			merged = append(merged, game)
+		} else if s.progress(game) >= s.progress(merged[i]) {
+			merged[i] = game
+		}
+	}
+	if merged == nil {
+		merged = []Game{}
+	}
+	return merged
+}
+
+// How far along a game is, finished games always beat unfinished ones
+func (s SaveData) progress(g Game) int {
+	p := g.Attempts() + len(g.Hints) + len(g.RevealedQuoteIDs)
+	// Games straight from json don't know their book yet, so Won needs Init first.
+	// If the quote is gone only losses can count as finished.
+	_ = g.Init(s)
+	if g.Completed() {
+		p += 1000
+	}
+	return p
+}
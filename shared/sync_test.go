@@ -0,0 +1,77 @@
+package shared
+
+import (
+	"encoding/json"
+	"testing"
+)
+
+// Sends the player through json like a client syncing to the server does
+func roundTrip(t *testing.T, player Player) Player {
+	t.Helper()
+	b, err := json.Marshal(player)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var decoded Player
+	if err := json.Unmarshal(b, &decoded); err != nil {
+		t.Fatal(err)
+	}
+	return decoded
+}
+
+func TestMergePlayerKeepsBothClientsGames(t *testing.T) {
+	data := newTestSave(3, 2)
+	finished := Game{Day: testDay, QuoteID: 100000, Guesses: []BookId{1000}}
+	data.Player.SeenQuotes = []QuoteId{100000}
+	data.Player.Games = []Game{finished, {Day: "2025-03-15", Mode: AuthorMode}}
+	data.Player = roundTrip(t, data.Player)
+
+	// This client loaded before the first game was finished elsewhere
+	incoming := Player{
+		SeenQuotes: []QuoteId{100100},
+		Games:      []Game{{Day: testDay}, {Day: "2025-03-16", QuoteID: 100100, Guesses: []BookId{1002}}},
+	}
+
+	merged := data.MergePlayer(roundTrip(t, incoming))
+	if len(merged.Games) != 3 {
+		t.Fatalf("Expected 3 games, got %+v", merged.Games)
+	}
+	if len(merged.Games[0].Guesses) != 1 {
+		t.Fatal("An unstarted copy replaced a game that was further along")
+	}
+	if len(merged.SeenQuotes) != 2 {
+		t.Fatalf("Expected both clients' seen quotes, got %v", merged.SeenQuotes)
+	}
+}
+
+func TestMergePlayerKeepsWonGameOverLongerUnfinishedOne(t *testing.T) {
+	data := newTestSave(3, 2)
+	won := Game{
+		Day:              testDay,
+		Mode:             MultiQuoteMode,
+		QuoteID:          100000,
+		Guesses:          []BookId{1001, 1000},
+		RevealedQuoteIDs: []QuoteId{100000, 100001},
+	}
+	data.Player.Games = []Game{won}
+	data.Player = roundTrip(t, data.Player)
+
+	// More guesses than the won copy, but still going
+	unfinished := Game{
+		Day:              testDay,
+		Mode:             MultiQuoteMode,
+		QuoteID:          100000,
+		Guesses:          []BookId{1001, 1002},
+		RevealedQuoteIDs: []QuoteId{100000, 100001},
+		Hints:            []Hint{UnredactHint},
+	}
+	incoming := roundTrip(t, Player{Games: []Game{unfinished}})
+
+	merged := data.MergePlayer(incoming)
+	if len(merged.Games) != 1 {
+		t.Fatalf("Expected 1 game, got %+v", merged.Games)
+	}
+	if got := merged.Games[0].Guesses; got[len(got)-1] != 1000 {
+		t.Fatalf("The won game was replaced by an unfinished copy, got guesses %v", got)
+	}
+}
@@ -0,0 +1,33 @@
+package shared
+
+import (
+	"regexp"
+	"strings"
+)
+
+// Goodreads puts the series after the title, e.g. "The Two Towers (The Lord of the Rings, #2)"
+var seriesSuffix = regexp.MustCompile(`^(.+?)\s*\(([^()]+?),?\s+#\s*([^()\s]+)\)$`)
+
+// Splits a Goodreads title into the title itself and the series it belongs to, if any
+func ParseTitle(title string) (base string, series string, seriesNumber string) {
+	title = strings.TrimSpace(strings.Join(strings.Fields(title), " "))
+	match := seriesSuffix.FindStringSubmatch(title)
+	if match == nil {
+		return title, "", ""
+	}
+	return match[1], strings.TrimSpace(match[2]), match[3]
+}
+
+// Fills BaseTitle, Series and SeriesNumber from Title
+func (b *Book) ParseTitle() {
+	b.BaseTitle, b.Series, b.SeriesNumber = ParseTitle(b.Title)
+}
+
+// The title without the series suffix
+func (b Book) ShortTitle() string {
+	if b.BaseTitle == "" {
+		base, _, _ := ParseTitle(b.Title)
+		return base
+	}
+	return b.BaseTitle
+}
@@ -0,0 +1,15 @@
+//go:build embed_site
+
+package site
+
+import (
+	"embed"
+	"io/fs"
+)
+
+// Needs js/main.wasm built first, `./build.sh embedded` does both
+//
+//go:embed *.html *.js *.json *.ico css js
+var files embed.FS
+
+var Files fs.FS = files
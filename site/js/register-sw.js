@@ -0,0 +1,5 @@
+if ("serviceWorker" in navigator) {
+	navigator.serviceWorker.register("sw.js").catch((err) => {
+		console.error("Failed registering service worker", err);
+	});
+}
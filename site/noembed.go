@@ -0,0 +1,8 @@
+//go:build !embed_site
+
+package site
+
+import "io/fs"
+
+// nil without the embed_site tag, the server then only hosts ./site when debugging
+var Files fs.FS
@@ -0,0 +1,3 @@
+// Package site is the web client. Building the server with the embed_site tag
+// bakes it into the binary as Files, see embed.go.
+package site
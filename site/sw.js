@@ -0,0 +1,75 @@
+// Caches the site so the daily game still loads without a connection.
+// CACHE_VERSION comes from sw-version.js, which build.sh writes for every build and deploy,
+// so a new build installs a new worker and fills a fresh cache.
+try {
+	importScripts("sw-version.js");
+} catch (e) {
+	// Not built, so there's nothing telling us when the cache is stale
+}
+
+// Without a version the cache is only trusted when the network fails
+const VERSIONED = typeof self.CACHE_VERSION === "string" && self.CACHE_VERSION !== "";
+const CACHE_NAME = `libble-${VERSIONED ? self.CACHE_VERSION : "unversioned"}`;
+const PRECACHE = [
+	"./",
+	"index.html",
+	"game.html",
+	"start.html",
+	"archive.html",
+	"favicon.ico",
+	"config.json",
+	"css/style.css",
+	"js/wasm_exec.js",
+	"js/register-sw.js",
+	"js/main.wasm",
+];
+
+self.addEventListener("install", (event) => {
+	event.waitUntil(
+		caches.open(CACHE_NAME)
+			// Skip the http cache so a new version never gets old files
+			.then((cache) => cache.addAll(PRECACHE.map((url) => new Request(url, { cache: "reload" }))))
+			.then(() => self.skipWaiting()),
+	);
+});
+
+self.addEventListener("activate", (event) => {
+	event.waitUntil(
+		caches.keys()
+			.then((names) => Promise.all(
+				names
+					.filter((name) => name.startsWith("libble-") && name !== CACHE_NAME)
+					.map((name) => caches.delete(name)),
+			))
+			.then(() => self.clients.claim()),
+	);
+});
+
+self.addEventListener("fetch", (event) => {
+	const request = event.request;
+	const url = new URL(request.url);
+	// The api lives on another origin and handles being offline itself
+	if (request.method !== "GET" || url.origin !== self.location.origin) {
+		return;
+	}
+
+	if (request.mode === "navigate" || !VERSIONED) {
+		// Pages come from the network when possible so they don't go stale
+		event.respondWith(
+			fetch(request)
+				.then((response) => {
+					if (response.ok) {
+						const copy = response.clone();
+						caches.open(CACHE_NAME).then((cache) => cache.put(request.mode === "navigate" ? url.pathname : request, copy));
+					}
+					return response;
+				})
+				.catch(() => caches.match(request, { ignoreSearch: true })),
+		);
+		return;
+	}
+
+	event.respondWith(
+		caches.match(request).then((cached) => cached || fetch(request)),
+	);
+});
@@ -0,0 +1,64 @@
+package main
+
+import (
+	"fmt"
+	"net/url"
+
+	. "libble/shared"
+
+	dom "honnef.co/go/js/dom/v2"
+)
+
+func initArchive() {
+	fmt.Println("Starting archive...")
+	var data SaveData
+	if err := loadAllData(&data); err != nil {
+		log(err, "Failed loading data when starting archive")
+	}
+	data.Migrate()
+
+	mode := currentMode()
+	setupModeTabs(mode)
+
+	doc := dom.GetWindow().Document()
+	list := doc.GetElementByID("archiveList")
+	if list == nil {
+		logErr("Failed to get html element with id 'archiveList'")
+		return
+	}
+
+	for _, day := range data.ArchiveDays(mode) {
+		li := doc.CreateElement("li")
+		li.Class().SetString("archive-day " + string(day.Status))
+
+		link := doc.CreateElement("a").(*dom.HTMLAnchorElement)
+		link.SetHref(PageGame + "?" + url.Values{dayParam: {day.Day}}.Encode())
+		link.SetTextContent(fmt.Sprintf("#%d  %s", PuzzleNumber(day.Day), day.Day))
+		li.AppendChild(link)
+
+		status := doc.CreateElement("span")
+		status.Class().Add("archive-status")
+		status.SetTextContent(archiveStatusText(day))
+		li.AppendChild(status)
+
+		list.AppendChild(li)
+	}
+}
+
+func archiveStatusText(day ArchiveDay) string {
+	text := ""
+	switch day.Status {
+	case WonDay:
+		text = "✅ Won"
+	case LostDay:
+		text = "❌ Lost"
+	case InProgressDay:
+		text = "⏳ In progress"
+	default:
+		return "Play"
+	}
+	if day.Archive {
+		text += " (archive)"
+	}
+	return text
+}
@@ -1,9 +1,10 @@
 package main
 
 import (
+	"encoding/json"
 	"errors"
 	"fmt"
-	"math"
+	"math/rand"
 	"reflect"
 	"slices"
 	"strings"
@@ -21,29 +22,46 @@ func saveKey(jsonName string) string {
 
 type FieldPredicate func(string) bool
 
-func saveAllDataFiltered(data SaveData, filter FieldPredicate) error {
+// Marshals the fields now and writes them in the background, in order.
+// The channels report when each write lands.
+func saveAllDataFiltered(data SaveData, filter FieldPredicate) ([]chan error, error) {
 	v := reflect.ValueOf(data)
 	t := v.Type()
 	var err error
-	err = nil
+	written := make([]chan error, 0, t.NumField())
 	for i := range t.NumField() {
 		field := t.Field(i)
 		jsonName := field.Tag.Get("json")
-		if jsonName != "" && filter(jsonName) {
-			err = errors.Join(err, saveJson(saveKey(jsonName), v.Field(i).Interface()))
+		if jsonName == "" || !filter(jsonName) {
+			continue
+		}
+		jsonBytes, marshalErr := json.Marshal(v.Field(i).Interface())
+		if marshalErr != nil {
+			err = errors.Join(err, fmt.Errorf("Failed to marshal %s: %v", jsonName, marshalErr))
+			continue
 		}
+		// Books and quotes are most of the save, so they get compressed
+		written = append(written, queueSave(saveKey(jsonName), jsonBytes, IsStaticSaveDataField(jsonName)))
 	}
-	return err
+	return written, err
 }
 
+// Blocks until everything is written, so call it from a goroutine
 func saveAllData(data SaveData) error {
-	return saveAllDataFiltered(data, func(s string) bool { return true })
+	written, err := saveAllDataFiltered(data, func(s string) bool { return true })
+	for _, done := range written {
+		err = errors.Join(err, <-done)
+	}
+	return err
 }
 
+// Safe to call from js callbacks, failed writes are logged by the save queue
 func saveNonStaticData(data SaveData) error {
-	return saveAllDataFiltered(data, func(s string) bool { return !IsStaticSaveDataField(s) })
+	_, err := saveAllDataFiltered(data, func(s string) bool { return !IsStaticSaveDataField(s) })
+	return err
 }
 
+// Blocks on storage, so call it from a goroutine
 func loadAllData(data *SaveData) error {
 	pv := reflect.ValueOf(data)
 	v := pv.Elem()
@@ -61,6 +79,17 @@ func loadAllData(data *SaveData) error {
 	return err
 }
 
+const modeKey = "mode"
+
+func currentMode() GameMode {
+	mode, err := loadData(modeKey)
+	log(err, "Failed loading game mode")
+	if slices.Contains(GameModes, GameMode(mode)) {
+		return GameMode(mode)
+	}
+	return ClassicMode
+}
+
 func initGame() {
 	fmt.Println("Starting game...")
 	var data SaveData
@@ -68,71 +97,99 @@ func initGame() {
 	if err := loadAllData(&data); err != nil {
 		log(err, "Failed loading data when starting game")
 	}
+	if data.Player.Timezone == "" {
+		data.Player.Timezone = browserTimezone()
+	}
+	data.Migrate()
 
-	if _, err := initTodaysGame(&data); err != nil {
+	mode := currentMode()
+	var game *Game
+	var err error
+	if isPractice() {
+		game, err = initPracticeGame(&data, mode)
+		log(err, "Failed initializing practice game")
+	} else if day := queryParams().Get(dayParam); day != "" && day < data.Player.Today() {
+		game, err = data.StartDailyGame(day, mode)
+		log(err, "Failed initializing archive game")
+	} else {
+		game, err = data.StartDailyGame(data.Player.Today(), mode)
 		log(err, "Failed initializing today's game")
 	}
 
 	fmt.Println("Setting update autocomplete")
 
-	// convert book map to slice
-	bookCount := len(data.Books)
-	allBooks := make([]Book, 0, bookCount)
-	for _, book := range data.Books {
-		allBooks = append(allBooks, book.Book)
-	}
-
-	setupHTML(&data, allBooks)
-}
-func toDate(t time.Time) time.Time {
-	return t.Truncate(24 * time.Hour)
+	setupModeTabs(mode)
+	setupHTML(&data, game, guessSource(data, mode))
 }
 
-func todaysDate() time.Time {
-	return time.Now().Truncate(24 * time.Hour)
+// What the autocomplete suggests for the mode
+func guessSource(data SaveData, mode GameMode) fuzzy.Source {
+	if mode == AuthorMode {
+		return data.Authors()
+	}
+	return data.SortedBooks()
 }
 
-func todaysGame(data *SaveData) *Game {
-	player := &data.Player
-	if len(player.Games) > 0 {
-		MaxLookback := 5
-		lastValidIndex := max(len(player.Games)-MaxLookback-1, 0)
-		for i := len(player.Games) - 1; i >= lastValidIndex; i-- {
-			game := &player.Games[i]
-			if todaysDate().Equal(toDate(game.Date)) {
-				return game
-			}
-		}
+// What else the autocomplete searches, the authors when guessing books
+func searchDetails(source fuzzy.Source) fuzzy.Source {
+	if books, ok := source.(Books); ok {
+		return BookAuthors(books)
 	}
 	return nil
 }
-
-func initTodaysGame(data *SaveData) (game *Game, err error) {
-	if game = todaysGame(data); game != nil {
-		err = game.Init(*data)
-		return game, err
+func initPracticeGame(data *SaveData, mode GameMode) (*Game, error) {
+	if game := data.CurrentPracticeGame(mode); game != nil {
+		return game, nil
 	}
 
-	dailyQuoteId, err := data.PickDailyQuote()
+	rng := rand.New(rand.NewSource(time.Now().UnixNano()))
+	quoteId, err := data.PickPracticeQuote(mode, rng)
 	if err != nil {
-		return game, fmt.Errorf("Failed to pick daily quote when making new game:\n%v", err)
+		return nil, fmt.Errorf("Failed to pick practice quote when making new game:\n%v", err)
 	}
 
 	player := &data.Player
-	player.Games = append(player.Games, Game{
-		QuoteID: dailyQuoteId,
-		Date:    time.Now(),
-		Guesses: make([]BookId, 0),
+	player.PracticeGames = append(player.PracticeGames, Game{
+		Mode:      mode,
+		QuoteID:   quoteId,
+		Day:       player.Today(),
+		StartedAt: time.Now(),
+		Guesses:   make([]BookId, 0),
+		Practice:  true,
 	})
-	game = &player.Games[len(player.Games)-1]
-	err = game.Init(*data)
-	return game, err
+	game := &player.PracticeGames[len(player.PracticeGames)-1]
+	return game, game.Init(*data)
 }
 
-func setupHTML(data *SaveData, allBooks Books) {
+func setupModeTabs(current GameMode) {
 	doc := dom.GetWindow().Document()
+	tabs := doc.GetElementByID("modeTabs")
+	if tabs == nil {
+		return
+	}
+
+	for _, mode := range GameModes {
+		button := doc.CreateElement("button").(*dom.HTMLButtonElement)
+		button.SetAttribute("type", "button")
+		button.SetTextContent(mode.String())
+		button.Class().Add("mode-tab")
+		if mode == current {
+			button.Class().Add("selected")
+		}
+		button.AddEventListener("click", false, func(e dom.Event) {
+			e.PreventDefault()
+			if mode == current {
+				return
+			}
+			log(saveData(modeKey, string(mode)), "Failed saving game mode")
+			dom.GetWindow().Location().Call("reload")
+		})
+		tabs.AppendChild(button)
+	}
+}
 
-	game := todaysGame(data)
+func setupHTML(data *SaveData, game *Game, source fuzzy.Source) {
+	doc := dom.GetWindow().Document()
 
 	defer func() {
 		if r := recover(); r != nil {
@@ -140,20 +197,37 @@ func setupHTML(data *SaveData, allBooks Books) {
 		}
 	}()
 
-	quoteElement := doc.GetElementByID("quote")
-	if quoteElement != nil {
-		quoteElement.SetTextContent(game.Quote.Text)
+	quoteBox := doc.GetElementByID("quoteBox")
+	renderQuote := func() {
+		if quoteBox == nil {
+			return
+		}
+		quoteBox.SetInnerHTML("")
+		for _, text := range game.QuoteTexts() {
+			p := doc.CreateElement("p")
+			p.Class().Add("quote-text")
+			p.SetTextContent(text)
+			quoteBox.AppendChild(p)
+		}
 	}
+	renderQuote()
 
 	input := doc.GetElementByID("title").(*dom.HTMLInputElement)
 	suggestions := doc.GetElementByID("titleSuggestions").(dom.HTMLElement)
 	guessForm := doc.GetElementByID("guessForm")
 
+	if game.Mode == AuthorMode {
+		if label := doc.GetElementByID("guessLabel"); label != nil {
+			label.SetTextContent("Author:")
+		}
+		input.SetPlaceholder("Start typing to see the authors in your library")
+	}
+
 	feedback, feedbackOk := doc.GetElementByID("feedbackBox").(dom.HTMLElement)
 	if !feedbackOk {
 		logErr("Failed to get html element with id 'feedbackBox'")
 	}
-	setFeedback := func(msg string, status string) {
+	setFeedback := func(msg string, status FeedbackStatus) {
 		if feedbackOk {
 			setFeedbackElem(feedback, msg, status)
 		}
@@ -163,7 +237,7 @@ func setupHTML(data *SaveData, allBooks Books) {
 	if !statusBoxOk {
 		logErr("Failed to get html element with id 'statusBox'")
 	}
-	setStatus := func(msg string, status string) {
+	setStatus := func(msg string, status FeedbackStatus) {
 		if statusBoxOk {
 			setFeedbackElem(statusBox, msg, status)
 			setFeedback("", "")
@@ -172,6 +246,7 @@ func setupHTML(data *SaveData, allBooks Books) {
 
 	gameInputs := doc.GetElementsByClassName("game-input")
 	skipBtn := doc.GetElementByID("skipBtn")
+	unredactHintBtn := doc.GetElementByID("unredactHintBtn")
 	updateInputStates := func() {
 		defer func() {
 			if r := recover(); r != nil {
@@ -183,168 +258,298 @@ func setupHTML(data *SaveData, allBooks Books) {
 			e.Underlying().Set("disabled", disabled)
 		}
 		skipBtn.Underlying().Set("disabled", game.Started())
+		unredactHintBtn.Underlying().Set("disabled", !game.IsRedacted())
 	}
 
+	controller := NewController(data, game)
+
 	handleRevist := func() bool {
-		if !game.Completed() {
-			setStatus("", "")
-			return true
+		status, ok := controller.Status()
+		setStatus(status.Message, status.Status)
+		return ok
+	}
+
+	guessList := doc.GetElementByID("guessList")
+	renderGuesses := func() {
+		if guessList != nil {
+			renderGuessList(guessList, data, game)
 		}
-		if game.Won() {
-			setStatus("Congrats! You've already won for today, \ncome back tomorrow to play again.", SuccessFBStatus)
-		} else {
-			setStatus("Looks like you didn't get it this time :(\nCome back tomorrow and try again!", "")
+	}
+
+	practiceBtn := doc.GetElementByID("practiceBtn")
+	shareBtn := doc.GetElementByID("shareBtn")
+	updatePracticeBtn := func() {
+		if practiceBtn != nil {
+			practiceBtn.Underlying().Set("hidden", !game.Completed())
+		}
+		if shareBtn != nil {
+			shareBtn.Underlying().Set("hidden", !game.Completed())
+		}
+	}
+	if shareBtn != nil {
+		shareBtn.AddEventListener("click", false, func(e dom.Event) {
+			e.PreventDefault()
+			onShare(data, game, setFeedback)
+		})
+	}
+	if practiceBtn != nil {
+		if game.Practice {
+			practiceBtn.SetTextContent("🔁 Next Quote")
 		}
-		return false
+		practiceBtn.AddEventListener("click", false, func(e dom.Event) {
+			e.PreventDefault()
+			if game.Practice {
+				dom.GetWindow().Location().Call("reload")
+			} else {
+				location().SetHref(PageGame + "?" + practiceParam)
+			}
+		})
 	}
+	if game.Practice || game.Day != data.Player.Today() {
+		if dailyLink := doc.GetElementByID("dailyLink"); dailyLink != nil {
+			dailyLink.Underlying().Set("hidden", false)
+		}
+	}
+	if subtitle := doc.GetElementByID("subtitle"); subtitle != nil {
+		if game.Practice {
+			subtitle.SetTextContent("Practice mode, these games don't count toward your stats")
+		} else if game.Day != data.Player.Today() {
+			subtitle.SetTextContent(fmt.Sprintf("Archive: Libble #%d from %s", PuzzleNumber(game.Day), game.Day))
+		}
+	}
+
+	showStats := setupStats(data, game)
+	setupTransfer(data)
+	setupSettings(data)
 
 	handleRevist()
 	updateInputStates()
+	updatePracticeBtn()
+	renderGuesses()
+
+	guessChoices := doc.GetElementByID("guessChoices")
+	var showChoices func(bookIds []BookId)
+
+	// Shows whatever the controller did
+	render := func(result Result) {
+		if result.Has(SkippedEvent) || result.Has(RejectedEvent) && game.Completed() {
+			setStatus(result.Message, result.Status)
+		} else {
+			setFeedback(result.Message, result.Status)
+		}
+		if len(result.Choices) > 0 {
+			showChoices(result.Choices)
+		}
+		if !result.Changed() {
+			return
+		}
+
+		log(saveNonStaticData(*data), "Failed saving game")
+		renderQuote()
+		renderGuesses()
+		updateInputStates()
+		updatePracticeBtn()
+		if result.Has(CompletedEvent) && !game.Practice {
+			syncPlayer(data.Player)
+			showStats()
+		}
+	}
+
+	showChoices = func(bookIds []BookId) {
+		if guessChoices == nil {
+			return
+		}
+		renderChoices(guessChoices, data, bookIds, func(bookId BookId) {
+			render(controller.GuessBook(bookId))
+		})
+	}
+
+	// The book the player picked from the suggestions, cleared once they edit the input
+	var selectedBookId BookId = NilID
+	onSuggestionPicked := func(i int) {
+		if books, ok := source.(Books); ok {
+			selectedBookId = books[i].Id
+		}
+	}
+	input.AddEventListener("input", false, func(e dom.Event) {
+		selectedBookId = NilID
+	})
 
-	// setup submit
 	guessForm.AddEventListener("submit", false, func(e dom.Event) {
 		e.PreventDefault()
-		if handleRevist() {
-			onSubmit(input, data, setFeedback)
-			updateInputStates()
+		if guessChoices != nil {
+			guessChoices.SetInnerHTML("")
+		}
+		if game.Mode == AuthorMode {
+			render(controller.GuessAuthor(input.Value()))
+		} else if selectedBookId != NilID {
+			render(controller.GuessBook(selectedBookId))
+		} else {
+			render(controller.GuessTitle(input.Value()))
 		}
 	})
 
+	// setup hints
+	unredactHintBtn.AddEventListener("click", false, func(e dom.Event) {
+		e.PreventDefault()
+		render(controller.UseHint(UnredactHint))
+	})
+
 	// setup skip button
 	skipBtn.AddEventListener("click", false, func(e dom.Event) {
 		e.PreventDefault()
-		if game.Attempts() <= 0 && handleRevist() {
-			err := onSkip(data, setStatus)
-			log(err, "Failed skipping current quote")
-
-			quoteElement.SetTextContent(game.Quote.Text)
-			updateInputStates()
+		if !handleRevist() {
+			return
 		}
+		result, err := controller.Skip(rand.New(rand.NewSource(time.Now().UnixNano())))
+		log(err, "Failed skipping current quote")
+		render(result)
 	})
 
-	setupAutocomplete(input, suggestions, allBooks)
+	setSource := setupAutocomplete(input, suggestions, source, searchDetails(source), onSuggestionPicked)
+
+	libraryChanged := func() {
+		controller.LibraryChanged()
+		source = guessSource(*data, game.Mode)
+		setSource(source, searchDetails(source))
+	}
+	setupRefreshLibrary(data, setFeedback, libraryChanged)
+	go upgradeLegacySave(data, libraryChanged)
 }
 
-const (
-	// Feedback statuses
-	SuccessFBStatus = "successs"
-	ErrorFBStatus   = "error"
-	WarnFBStatus    = "warning"
-)
+func renderGuessList(list dom.Element, data *SaveData, game *Game) {
+	doc := dom.GetWindow().Document()
+	list.SetInnerHTML("")
+
+	if game.Mode == AuthorMode {
+		authors := data.Authors()
+		for i := len(game.AuthorGuesses) - 1; i >= 0; i-- {
+			guessGRID := game.AuthorGuesses[i]
+			index := slices.IndexFunc(authors, func(a Author) bool { return a.GRID == guessGRID })
+			if index < 0 {
+				continue
+			}
+
+			li := doc.CreateElement("li")
+			title := doc.CreateElement("div")
+			title.Class().Add("guess-title")
+			if guessGRID == game.Book.Book.AuthorGRID {
+				title.SetTextContent("✅ " + authors[index].Name)
+			} else {
+				title.SetTextContent("❌ " + authors[index].Name)
+			}
+			li.AppendChild(title)
+			list.AppendChild(li)
+		}
+		return
+	}
+	for i := len(game.Guesses) - 1; i >= 0; i-- {
+		guessId := game.Guesses[i]
+		guess, found := data.Books[guessId]
+		if !found {
+			continue
+		}
+
+		li := doc.CreateElement("li")
+		title := doc.CreateElement("div")
+		title.Class().Add("guess-title")
+		li.AppendChild(title)
 
-func setFeedbackElem(e dom.HTMLElement, message string, status string) {
+		if guessId == game.BookId {
+			title.SetTextContent("✅ " + guess.Book.CleanTitle())
+		} else {
+			title.SetTextContent("❌ " + guess.Book.CleanTitle())
+			for _, clue := range CompareBooks(guess, game.Book) {
+				span := doc.CreateElement("span")
+				span.Class().SetString("clue " + clue.Result.String())
+				span.SetTextContent(clue.String())
+				li.AppendChild(span)
+			}
+		}
+		list.AppendChild(li)
+	}
+}
+
+func setFeedbackElem(e dom.HTMLElement, message string, status FeedbackStatus) {
 	emoji := ""
 	switch status {
-	case ErrorFBStatus:
+	case ErrorStatus:
 		emoji = "❌"
-	case SuccessFBStatus:
+	case SuccessStatus:
 		emoji = "🎉"
-	case WarnFBStatus:
+	case WarnStatus:
 		emoji = "⚠️"
 	}
 
+	// The box is a live region, so screen readers read the message but skip the emoji
+	e.SetTextContent(message)
 	if emoji != "" {
-		e.SetTextContent(emoji + " " + message)
-	} else {
-		e.SetTextContent(message)
-	}
-	e.Class().SetString("feedback " + status)
-}
-
-func onSubmit(
-	input *dom.HTMLInputElement,
-	data *SaveData,
-	setFeedback func(msg string, status string),
-) bool {
-
-	query := strings.ToLower(strings.TrimSpace(input.Value()))
-	game := todaysGame(data)
-	target := strings.ToLower(game.Book.Book.CleanTitle())
-
-	defer saveNonStaticData(*data)
-
-	if query == target {
-		game.Guesses = append(game.Guesses, game.Quote.BookId)
-		attempts := len(game.Guesses)
-		s := ""
-		if attempts > 1 {
-			s = "s"
-		}
-		message := fmt.Sprintf("Correct! You got it in %d attempt%s", attempts, s)
-		setFeedback(message, SuccessFBStatus)
-		return true
-	} else if bookId := data.FindBookId(query); bookId != NilID {
-		if slices.Contains(game.Guesses, bookId) {
-			setFeedback("You already tried that guess!", WarnFBStatus)
-		} else {
-			game.Guesses = append(game.Guesses, bookId)
-
-			if len(game.Guesses) == MaxGuesses {
-				msg := fmt.Sprintf("Failed! The answer was \"%s\"", game.Book.Book.CleanTitle())
-				setFeedback(msg, ErrorFBStatus)
-				return true
-			} else {
-				msg := fmt.Sprintf("Nope! Try again (%d attempts remaining)",
-					MaxGuesses-len(game.Guesses))
-				setFeedback(msg, ErrorFBStatus)
-			}
-		}
-	} else {
-		setFeedback("That book is not in your library!", WarnFBStatus)
+		span := dom.GetWindow().Document().CreateElement("span")
+		span.SetAttribute("aria-hidden", "true")
+		span.SetTextContent(emoji + " ")
+		e.Underlying().Call("prepend", span.Underlying())
 	}
-	return false
+	e.Class().SetString("feedback " + string(status))
 }
 
-func onSkip(
+// Lets the player pick between books their guess was close to
+func renderChoices(
+	parent dom.Element,
 	data *SaveData,
-	setFeedback func(msg string, status string),
-) error {
-	game := todaysGame(data)
+	bookIds []BookId,
+	onPick func(bookId BookId),
+) {
+	doc := dom.GetWindow().Document()
+	parent.SetInnerHTML("")
 
-	if game.Started() {
-		return fmt.Errorf("Trying to skip after the game already started")
+	label := func(book Book) string {
+		return fmt.Sprintf("%s by %s", book.CleanTitle(), book.Author)
 	}
-
-	defer saveNonStaticData(*data)
-	// Mark quote as seen so it won't appear again
-	if !slices.Contains(data.Player.SeenQuotes, game.QuoteID) {
-		data.Player.SeenQuotes = append(data.Player.SeenQuotes, game.QuoteID)
+	labelCounts := map[string]int{}
+	for _, bookId := range bookIds {
+		labelCounts[label(data.Books[bookId].Book)]++
 	}
 
-	msg := fmt.Sprintf("Skipped! The answer was \"%s\"", game.Book.Book.CleanTitle())
-	setFeedback(msg, ErrorFBStatus)
-
-	dailyQuoteId, err := data.PickDailyQuote()
-	if err != nil {
-		return fmt.Errorf("Failed to pick daily quote when skipping:\n%v", err)
-	}
-	game.QuoteID = dailyQuoteId
-	game.Date = time.Now()
-	if err := game.Init(*data); err != nil {
-		return err
+	for _, bookId := range bookIds {
+		book, found := data.Books[bookId]
+		if !found {
+			continue
+		}
+		button := doc.CreateElement("button")
+		button.SetAttribute("type", "button")
+		button.Class().Add("choice-btn")
+		text := label(book.Book)
+		if labelCounts[text] > 1 { // separate editions of the same book
+			text += fmt.Sprintf(" (edition %s)", book.Book.BookGRID)
+		}
+		button.SetTextContent(text)
+		button.AddEventListener("click", false, func(e dom.Event) {
+			e.PreventDefault()
+			parent.SetInnerHTML("")
+			onPick(bookId)
+		})
+		parent.AppendChild(button)
 	}
-	return nil
 }
 
+// The list scrolls, this just keeps it from getting huge for short queries
+const maxSuggestions = 80
+
 func setupAutocomplete(
 	input *dom.HTMLInputElement,
 	suggestionsParent dom.HTMLElement,
-	allBooks Books /* available books */) {
+	source fuzzy.Source, /* available books or authors */
+	details fuzzy.Source, /* searched too and shown with each suggestion, can be nil */
+	onPick func(sourceIndex int), /* called whenever a suggestion is put in the input */
+) (setSource func(source fuzzy.Source, details fuzzy.Source)) {
 
 	doc := dom.GetWindow().Document()
 
-	type Suggestion struct {
-		bookIndex  int
-		titleMatch fuzzy.Match
-	}
-
-	suggestions := make([]Suggestion, 0, len(allBooks))
+	suggestions := make([]SearchMatch, 0, maxSuggestions)
 	currentSelection := 0
-	const maxVisibleSuggestions = 8
 
-	getBook := func(suggestionIndex int) Book {
-		suggestion := suggestions[suggestionIndex]
-		return allBooks[suggestion.bookIndex]
+	getText := func(suggestionIndex int) string {
+		return source.String(suggestions[suggestionIndex].Index)
 	}
 
 	updateSuggestions := func() {}
@@ -355,15 +560,36 @@ func setupAutocomplete(
 		updateSuggestions()
 	}
 
+	pickSelection := func() {
+		input.SetValue(getText(currentSelection))
+		onPick(suggestions[currentSelection].Index)
+	}
+
 	useSelection := func() {
-		input.SetValue(getBook(currentSelection).CleanTitle())
+		pickSelection()
 		resetSuggestions()
 	}
 
+	optionId := func(suggestionIndex int) string {
+		return fmt.Sprintf("%s-option-%d", suggestionsParent.ID(), suggestionIndex)
+	}
+
+	// Only moves the highlight so the list keeps its scroll position
 	setSelection := func(selection int) {
+		items := suggestionsParent.QuerySelectorAll("li")
+		if currentSelection < len(items) {
+			items[currentSelection].Class().Remove("selected")
+			items[currentSelection].SetAttribute("aria-selected", "false")
+		}
 		currentSelection = selection
-		updateSuggestions()
-		input.SetValue(getBook(currentSelection).CleanTitle())
+		if currentSelection < len(items) {
+			item := items[currentSelection]
+			item.Class().Add("selected")
+			item.SetAttribute("aria-selected", "true")
+			item.Underlying().Call("scrollIntoView", map[string]any{"block": "nearest"})
+			input.SetAttribute("aria-activedescendant", optionId(currentSelection))
+		}
+		pickSelection()
 	}
 
 	updateSuggestions = func() {
@@ -371,9 +597,10 @@ func setupAutocomplete(
 
 		setDisplay := func(val string) {
 			suggestionsParent.Style().SetProperty("display", val, "important")
+			input.SetAttribute("aria-expanded", fmt.Sprint(val != "none"))
 		}
+		input.RemoveAttribute("aria-activedescendant")
 
-		fmt.Printf("Updating suggestions\n")
 		if len(suggestions) == 0 {
 			setDisplay("none")
 			return
@@ -381,47 +608,44 @@ func setupAutocomplete(
 
 		for i, suggestion := range suggestions {
 			li := doc.CreateElement("li")
-
-			book := allBooks[suggestion.bookIndex]
-
-			li.SetTextContent(book.CleanTitle())
+			li.SetID(optionId(i))
+			li.SetAttribute("role", "option")
+			li.SetAttribute("aria-selected", fmt.Sprint(i == currentSelection))
+
+			appendHighlighted(li, source.String(suggestion.Index), suggestion.LabelIndexes)
+			if details != nil {
+				if text := details.String(suggestion.Index); text != "" {
+					span := doc.CreateElement("span")
+					span.Class().Add("suggestion-detail")
+					appendHighlighted(span, text, suggestion.DetailIndexes)
+					li.AppendChild(span)
+				}
+			}
 			if i == currentSelection {
 				li.Class().Add("selected")
+				input.SetAttribute("aria-activedescendant", li.ID())
 			}
 			li.AddEventListener("click", false, func(e dom.Event) {
 				setSelection(i)
 				useSelection()
 			})
 			suggestionsParent.AppendChild(li)
-			if i >= maxVisibleSuggestions {
-				break
-			}
 		}
 		setDisplay("block")
+		suggestionsParent.Underlying().Set("scrollTop", 0)
 	}
 
 	input.AddEventListener("input", false, func(e dom.Event) {
 		query := strings.TrimSpace(input.Value())
-		fmt.Println("Input callback")
 
-		matches := fuzzy.FindFrom(query, allBooks)
-		count := min(len(matches), int(80))
-		suggestions = suggestions[:0]
+		matches := Search(query, source, details)
+		suggestions = append(suggestions[:0], matches[:min(len(matches), maxSuggestions)]...)
 		currentSelection = 0
-		for i := range count {
-			match := matches[i]
-			suggestions = append(suggestions, Suggestion{
-				bookIndex:  match.Index,
-				titleMatch: match,
-			})
-		}
-
-		// Find matching books
 		updateSuggestions()
 	})
 
 	input.AddEventListener("keydown", false, func(e dom.Event) {
-		if suggestionsParent.InnerHTML() == "" {
+		if len(suggestions) == 0 {
 			return
 		}
 		keyEvent := e.(*dom.KeyboardEvent)
@@ -433,22 +657,19 @@ func setupAutocomplete(
 			setSelection((currentSelection + 1) % len(suggestions))
 		case "ArrowUp":
 			e.PreventDefault()
-			if currentSelection == 0 {
-				setSelection(maxVisibleSuggestions - 1)
-			} else {
-				setSelection(currentSelection - 1)
-			}
+			setSelection((currentSelection - 1 + len(suggestions)) % len(suggestions))
 		case "Enter":
 			e.PreventDefault()
 			useSelection()
-			// TODO: submit game
+			if form := input.Underlying().Get("form"); form.Truthy() {
+				form.Call("requestSubmit")
+			}
 		case "Tab":
-			e.PreventDefault()
-			input.SetValue(getBook(currentSelection).CleanTitle())
+			// Accepts the suggestion but lets focus move on, like other comboboxes
+			useSelection()
 		case "Escape":
 			resetSuggestions()
 		}
-		fmt.Println("Current:", currentSelection)
 	})
 
 	// Hide suggestions when clicking outside
@@ -458,6 +679,44 @@ func setupAutocomplete(
 			resetSuggestions()
 		}
 	})
+
+	return func(newSource fuzzy.Source, newDetails fuzzy.Source) {
+		source = newSource
+		details = newDetails
+		resetSuggestions()
+	}
+}
+
+// Adds text to parent with the characters at matched (byte indexes) wrapped in <mark>
+func appendHighlighted(parent dom.Element, text string, matched []int) {
+	doc := dom.GetWindow().Document()
+	isMatched := make(map[int]bool, len(matched))
+	for _, i := range matched {
+		isMatched[i] = true
+	}
+
+	runStart := 0
+	runMatched := false
+	flush := func(end int) {
+		if end <= runStart {
+			return
+		}
+		if runMatched {
+			mark := doc.CreateElement("mark")
+			mark.SetTextContent(text[runStart:end])
+			parent.AppendChild(mark)
+		} else {
+			parent.AppendChild(doc.CreateTextNode(text[runStart:end]))
+		}
+		runStart = end
+	}
+	for i := range text { // ranging a string steps over whole runes
+		if isMatched[i] != runMatched {
+			flush(i)
+			runMatched = isMatched[i]
+		}
+	}
+	flush(len(text))
 }
 
 // func fuzzyScore(query, ) {
@@ -479,54 +738,6 @@ func setupAutocomplete(
 // 	}
 // }
 
-func LevenshteinDistance(s, t string) int {
-	r1, r2 := []rune(s), []rune(t)
-	column := make([]int, 1, 64)
-
-	for y := 1; y <= len(r1); y++ {
-		column = append(column, y)
-	}
-
-	for x := 1; x <= len(r2); x++ {
-		column[0] = x
-
-		for y, lastDiag := 1, x-1; y <= len(r1); y++ {
-			oldDiag := column[y]
-			cost := 0
-			if r1[y-1] != r2[x-1] {
-				cost = 1
-			}
-			column[y] = min(column[y]+1, column[y-1]+1, lastDiag+cost)
-			lastDiag = oldDiag
-		}
-	}
-	return column[len(r1)]
-}
-
-func LevenshteinDistanceNorm(s1, s2 string) float64 {
-	distance := LevenshteinDistance(s1, s2)
-	maxLength := math.Max(float64(len(s1)), float64(len(s2)))
-
-	if maxLength == 0 { // Handle case where both strings are empty
-		return 0.0
-	}
-	return float64(distance) / maxLength
-}
-
-type Books []Book
-
-func (b Books) String(i int) string {
-	if i >= 0 && i < len(b) {
-		return b[i].CleanTitle()
-	}
-	logErr(fmt.Sprintf("Fuzzy search is trying to use index %d", i))
-	return ""
-}
-
-func (b Books) Len() int {
-	return len(b)
-}
-
 // func findMatchingBooks(query string, book []Book) []Suggestion {
 // 	if query == "" {
 // 		return nil
@@ -0,0 +1,81 @@
+package main
+
+import (
+	"fmt"
+	"net/http"
+	"strconv"
+	"time"
+
+	. "libble/shared"
+
+	dom "honnef.co/go/js/dom/v2"
+)
+
+// Has the server scrape goodreads again, merging in anything new
+func fetchLibraryUpdate(player Player) (LibraryUpdate, error) {
+	var update LibraryUpdate
+	path := "/update/" + strconv.FormatUint(uint64(player.ID), 10)
+	err := fetchWithToken(path, player.Token, nil, &update, http.MethodGet)
+	return update, err
+}
+
+func setupRefreshLibrary(data *SaveData, setFeedback func(string, FeedbackStatus), onRefreshed func()) {
+	refreshBtn := dom.GetWindow().Document().GetElementByID("refreshLibraryBtn")
+	if refreshBtn == nil {
+		return
+	}
+	label := refreshBtn.TextContent()
+
+	refreshBtn.AddEventListener("click", false, func(e dom.Event) {
+		e.PreventDefault()
+		if !isOnline() {
+			setFeedback("You need to be online to refresh your library", WarnStatus)
+			return
+		}
+		refreshBtn.Underlying().Set("disabled", true)
+		setFeedback("Checking goodreads for new books, this can take a minute...", NoStatus)
+
+		// Scraping takes a while, so show how long it's been going
+		done := make(chan struct{})
+		go func() {
+			started := time.Now()
+			ticker := time.NewTicker(time.Second)
+			defer ticker.Stop()
+			for {
+				select {
+				case <-done:
+					return
+				case <-ticker.C:
+					refreshBtn.SetTextContent(fmt.Sprintf("🔄 Refreshing... %ds", int(time.Since(started).Seconds())))
+				}
+			}
+		}()
+
+		go func() {
+			defer func() {
+				close(done)
+				refreshBtn.SetTextContent(label)
+				refreshBtn.Underlying().Set("disabled", false)
+			}()
+
+			update, err := fetchLibraryUpdate(data.Player)
+			if err != nil {
+				log(err, "Failed refreshing library")
+				setFeedback("Couldn't refresh your library, try again later", ErrorStatus)
+				return
+			}
+
+			data.ApplyLibraryUpdate(update)
+			if err := saveAllData(*data); err != nil {
+				log(err, "Failed saving refreshed library")
+			}
+			onRefreshed()
+
+			if update.NewBooks == 0 && update.NewQuotes == 0 {
+				setFeedback("Your library is already up to date", SuccessStatus)
+				return
+			}
+			setFeedback(fmt.Sprintf("Found %d new books and %d new quotes", update.NewBooks, update.NewQuotes), SuccessStatus)
+		}()
+	})
+}
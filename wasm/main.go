@@ -74,10 +74,16 @@ func main() {
 		fmt.Println("Waiting for page to complete...")
 		time.Sleep(time.Millisecond * 1)
 	}
+	loadApiOrigin()
+	if isPage(PageGame) || isPage(PageArchive) {
+		setupSyncQueue()
+	}
 	if isPage(PageGame) {
 		initGame()
 	} else if isPage(PageStart) {
 		initStart()
+	} else if isPage(PageArchive) {
+		initArchive()
 	}
 
 	<-make(chan bool) // Prevents "Uncaught Error: Go program has already exited"
@@ -9,6 +9,10 @@ import (
 
 const PageGame = "/game.html"
 const PageStart = "/start.html"
+const PageArchive = "/archive.html"
+
+const practiceParam = "practice"
+const dayParam = "day"
 
 func location() *dom.URLUtils {
 	return dom.GetWindow().Location().URLUtils
@@ -32,6 +36,9 @@ func handlePage() string {
 	}
 
 	if userId != "" {
+		if isPage(PageArchive) {
+			return PageArchive
+		}
 		return redirect(PageGame)
 	} else {
 		return redirect(PageStart)
@@ -48,3 +55,16 @@ func currPage() string {
 	}
 	return curr
 }
+
+func queryParams() url.Values {
+	currUrl, err := url.Parse(location().Href())
+	if err != nil {
+		log(err, "Failed parsing window.location.href")
+		return url.Values{}
+	}
+	return currUrl.Query()
+}
+
+func isPractice() bool {
+	return queryParams().Has(practiceParam)
+}
@@ -0,0 +1,173 @@
+package main
+
+import (
+	"fmt"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	. "libble/shared"
+
+	dom "honnef.co/go/js/dom/v2"
+)
+
+// Start page query param the QR code fills the code in with
+const pairParam = "pair"
+
+type pairResponse struct {
+	Code      string    `json:"code"`
+	ExpiresAt time.Time `json:"expires_at"`
+}
+
+type redeemResponse struct {
+	ID    DBID   `json:"libble_id"`
+	Token string `json:"token"`
+}
+
+func requestPairCode(data *SaveData) (pairResponse, error) {
+	var res pairResponse
+	path := "/pair/" + strconv.FormatUint(uint64(data.Player.ID), 10)
+	err := fetchWithToken(path, data.Player.Token, nil, &res, http.MethodPost)
+	return res, err
+}
+
+// Saves from before tokens existed can't sync or pair, and the server won't give out a token
+// for an id anyone could know. So they move to a new save that starts with the same library,
+// keeping the games stored here, which get synced up afterwards.
+func upgradeLegacySave(data *SaveData, onUpgraded func()) {
+	if data.Player.Token != "" || data.Player.ID == NilID || data.Player.UserGRID == "" || !isOnline() {
+		return
+	}
+
+	var fresh SaveData
+	query := url.Values{
+		"timezone": {data.Player.Timezone},
+		"from":     {strconv.FormatUint(uint64(data.Player.ID), 10)},
+	}
+	path := "/user/" + url.PathEscape(data.Player.UserGRID) + "?" + query.Encode()
+	if err := fetch(path, &fresh, http.MethodPost); err != nil {
+		log(err, "Failed moving to a save with a token")
+		return
+	}
+
+	data.Player.ID = fresh.Player.ID
+	data.Player.Token = fresh.Player.Token
+	data.ApplyLibraryUpdate(LibraryUpdate{Books: fresh.Books, Quotes: fresh.Quotes})
+	log(saveData(userIdKey, strconv.FormatUint(uint64(data.Player.ID), 10)), "Failed saving new user id")
+	log(saveAllData(*data), "Failed saving upgraded save")
+	syncPlayer(data.Player)
+	onUpgraded()
+}
+
+func setupTransfer(data *SaveData) {
+	doc := dom.GetWindow().Document()
+	transferBtn := doc.GetElementByID("transferBtn")
+	modal := doc.GetElementByID("transferModal")
+	codeText := doc.GetElementByID("pairCode")
+	expiryText := doc.GetElementByID("pairExpiry")
+	qr := doc.GetElementByID("pairQr")
+	if transferBtn == nil || modal == nil || codeText == nil || expiryText == nil || qr == nil {
+		return
+	}
+
+	transferBtn.AddEventListener("click", false, func(e dom.Event) {
+		e.PreventDefault()
+		codeText.SetTextContent("…")
+		expiryText.SetTextContent("")
+		qr.Underlying().Set("hidden", true)
+		modal.Underlying().Call("showModal")
+
+		go func() {
+			res, err := requestPairCode(data)
+			if err != nil {
+				log(err, "Failed getting pairing code")
+				codeText.SetTextContent("")
+				expiryText.SetTextContent("Couldn't get a code, check your connection and try again")
+				return
+			}
+			codeText.SetTextContent(res.Code)
+			expiryText.SetTextContent(fmt.Sprintf("Expires at %s", res.ExpiresAt.Local().Format(time.Kitchen)))
+
+			qrUrl, err := url.JoinPath(apiOrigin, "pair", res.Code, "qr.png")
+			if err != nil {
+				log(err, "Failed making QR code url")
+				return
+			}
+			qr.SetAttribute("src", qrUrl)
+			qr.Underlying().Set("hidden", false)
+		}()
+	})
+
+	if closeBtn := doc.GetElementByID("closeTransferBtn"); closeBtn != nil {
+		closeBtn.AddEventListener("click", false, func(e dom.Event) {
+			e.PreventDefault()
+			modal.Underlying().Call("close")
+		})
+	}
+}
+
+// Trades a code from another device for its save
+func redeemPairCode(code string) (SaveData, error) {
+	var data SaveData
+	var redeemed redeemResponse
+	code = strings.ToUpper(strings.TrimSpace(code))
+	if err := fetch("/redeem/"+url.PathEscape(code), &redeemed, http.MethodPost); err != nil {
+		return data, err
+	}
+
+	path := "/save/" + strconv.FormatUint(uint64(redeemed.ID), 10)
+	if err := fetchWithToken(path, redeemed.Token, nil, &data, http.MethodGet); err != nil {
+		return data, err
+	}
+	data.Player.Token = redeemed.Token
+	data.Migrate()
+	return data, nil
+}
+
+func setupRedeem(showError func(string), hideError func()) {
+	doc := dom.GetWindow().Document()
+	form := doc.GetElementByID("pair-form")
+	input, ok := doc.GetElementByID("pairCode").(*dom.HTMLInputElement)
+	if form == nil || !ok {
+		return
+	}
+	if code := queryParams().Get(pairParam); code != "" {
+		input.SetValue(code)
+	}
+
+	form.AddEventListener("submit", false, func(e dom.Event) {
+		e.PreventDefault()
+		hideError()
+
+		submitButton, ok := doc.GetElementByID("pair-button").(*dom.HTMLButtonElement)
+		if !ok {
+			logErr("Failed to get pair button")
+			return
+		}
+		submitButton.SetDisabled(true)
+		submitText := submitButton.TextContent()
+		submitButton.SetTextContent("Loading...")
+
+		go func() {
+			defer func() {
+				submitButton.SetDisabled(false)
+				submitButton.SetTextContent(submitText)
+			}()
+
+			data, err := redeemPairCode(input.Value())
+			if err != nil {
+				log(err, "Failed redeeming pairing code")
+				showError("That code didn't work, it may have expired. Get a new one from your other device.")
+				return
+			}
+
+			saveData(userIdKey, strconv.FormatUint(uint64(data.Player.ID), 10))
+			if err := saveAllData(data); err != nil {
+				log(err, "Failed to save data for paired device")
+			}
+			location().SetHref(PageGame)
+		}()
+	})
+}
@@ -12,6 +12,68 @@ import (
 	"syscall/js"
 )
 
+// Where the server lives, see loadApiOrigin.
+// Can be set at build time with -ldflags "-X main.apiOrigin=https://example.com/"
+var apiOrigin = ""
+
+const (
+	apiOriginMeta = "libble-api-origin"
+	configPath    = "config.json"
+)
+
+type siteConfig struct {
+	ApiOrigin string `json:"api_origin"`
+}
+
+// Picks the api origin from, in order: the build flag, <meta name="libble-api-origin" content="...">,
+// the config.json served with the site, and finally the site's own origin.
+// Fetches, so call it from a goroutine before anything talks to the server.
+func loadApiOrigin() {
+	defer func() { fmt.Printf("Using api at %s\n", apiOrigin) }()
+	if apiOrigin != "" {
+		return
+	}
+
+	meta := js.Global().Get("document").Call("querySelector", `meta[name="`+apiOriginMeta+`"]`)
+	if meta.Truthy() {
+		if content := meta.Call("getAttribute", "content"); content.Truthy() {
+			apiOrigin = content.String()
+			return
+		}
+	}
+
+	var config siteConfig
+	if err := loadSiteConfig(&config); err != nil {
+		log(err, "Failed loading site config")
+	} else if config.ApiOrigin != "" {
+		apiOrigin = config.ApiOrigin
+		return
+	}
+
+	apiOrigin = location().Origin()
+}
+
+func loadSiteConfig(config *siteConfig) error {
+	pageUrl, err := url.Parse(location().Href())
+	if err != nil {
+		return err
+	}
+	configUrl := pageUrl.ResolveReference(&url.URL{Path: configPath})
+
+	res, err := http.Get(configUrl.String())
+	if err != nil {
+		return fmt.Errorf("Failed fetching %s\n%v", configUrl, err)
+	}
+	defer res.Body.Close()
+	if res.StatusCode == http.StatusNotFound {
+		return nil // Not every site has one
+	}
+	if res.StatusCode != http.StatusOK {
+		return fmt.Errorf("Fetching %s failed with %d", configUrl, res.StatusCode)
+	}
+	return json.NewDecoder(res.Body).Decode(config)
+}
+
 func logErr(context string) {
 	console := js.Global().Get("console")
 	console.Call("error", context)
@@ -25,6 +87,53 @@ func log(err error, context string) {
 	logErr(context + "\n" + err.Error())
 }
 
+// IANA timezone name the browser is using, empty if unavailable
+func browserTimezone() (timezone string) {
+	defer func() {
+		if r := recover(); r != nil {
+			logErr(fmt.Sprintf("Failed getting browser timezone: %v", r))
+		}
+	}()
+	options := js.Global().Get("Intl").Call("DateTimeFormat").Call("resolvedOptions")
+	if zone := options.Get("timeZone"); zone.Type() == js.TypeString {
+		timezone = zone.String()
+	}
+	return timezone
+}
+
+// Blocks until `promise` settles, so never call it straight from a js callback
+func await(promise js.Value) (js.Value, error) {
+	results := make(chan js.Value, 1)
+	errs := make(chan error, 1)
+
+	onResolve := js.FuncOf(func(this js.Value, args []js.Value) any {
+		if len(args) > 0 {
+			results <- args[0]
+		} else {
+			results <- js.Undefined()
+		}
+		return nil
+	})
+	defer onResolve.Release()
+	onReject := js.FuncOf(func(this js.Value, args []js.Value) any {
+		reason := "unknown reason"
+		if len(args) > 0 {
+			reason = args[0].Call("toString").String()
+		}
+		errs <- errors.New(reason)
+		return nil
+	})
+	defer onReject.Release()
+
+	promise.Call("then", onResolve, onReject)
+	select {
+	case result := <-results:
+		return result, nil
+	case err := <-errs:
+		return js.Undefined(), err
+	}
+}
+
 func saveData(key string, value string) (err error) {
 	defer func() {
 		if r := recover(); r != nil {
@@ -51,29 +160,6 @@ func compress(b []byte) ([]byte, error) {
 	return buffer.Bytes(), nil
 }
 
-func saveJson(key string, data any) error {
-	jsonBytes, err := json.Marshal(data)
-	if err != nil {
-		return errors.Join(fmt.Errorf("Failed to marshal %s", key), err)
-	}
-	saveData(key, string(jsonBytes))
-	return nil
-}
-
-func saveCompressedJson(key string, data any) error {
-	jsonBytes, err := json.Marshal(data)
-	if err != nil {
-		return errors.Join(fmt.Errorf("Failed to marshal %s", key), err)
-	}
-
-	compressedBytes, err := compress(jsonBytes)
-	if err != nil {
-		return errors.Join(fmt.Errorf("Failed to compress %s", key), err)
-	}
-	saveData(key, string(compressedBytes))
-	return nil
-}
-
 func loadData(key string) (stored string, err error) {
 	defer func() {
 		if r := recover(); r != nil {
@@ -89,14 +175,14 @@ func loadData(key string) (stored string, err error) {
 }
 
 func loadJson(key string, data any) error {
-	savedString, err := loadData(key)
+	saved, err := openStorage().get(key)
 	if err != nil {
 		return err
 	}
-	if savedString == "" {
+	if saved == nil {
 		return fmt.Errorf("No data was stored at %s", key)
 	}
-	err = json.Unmarshal([]byte(savedString), data)
+	err = json.Unmarshal(saved, data)
 	if err != nil {
 		return fmt.Errorf("Failed to unmarshel stored json at %s\n%v", key, err)
 	}
@@ -104,16 +190,48 @@ func loadJson(key string, data any) error {
 }
 
 func fetch(path string, data any, method string) error {
-	origin := "https://libble.onrender.com/"
-	url, err := url.JoinPath(origin, path)
+	return fetchWithBody(path, nil, data, method)
+}
+
+// Sends `body` as json when it isn't nil
+func fetchWithBody(path string, body any, data any, method string) error {
+	return fetchWithToken(path, "", body, data, method)
+}
+
+// Sends the player's token for endpoints that only let the owner in
+func fetchWithToken(path string, token string, body any, data any, method string) error {
+	origin := apiOrigin
+	pathUrl, err := url.Parse(path)
 	if err != nil {
 		return fmt.Errorf("Failed parsing path '%s'", path)
 	}
+	reqUrl, err := url.Parse(origin)
+	if err != nil {
+		return fmt.Errorf("Failed parsing origin '%s'", origin)
+	}
+	reqUrl = reqUrl.JoinPath(pathUrl.Path)
+	reqUrl.RawQuery = pathUrl.RawQuery
+	url := reqUrl.String()
+
+	var bodyReader io.Reader
+	if body != nil {
+		bodyBytes, err := json.Marshal(body)
+		if err != nil {
+			return fmt.Errorf("Failed to marshal body for %s\n%v", url, err)
+		}
+		bodyReader = bytes.NewReader(bodyBytes)
+	}
 
-	req, err := http.NewRequest(method, url, nil)
+	req, err := http.NewRequest(method, url, bodyReader)
 	if err != nil {
 		return fmt.Errorf("Failed to create request for %s\n%v", url, err)
 	}
+	if body != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
+	if token != "" {
+		req.Header.Set("Authorization", "Bearer "+token)
+	}
 	res, err := http.DefaultClient.Do(req)
 	if err != nil {
 		return fmt.Errorf("Failed fetching data for %s\n%v", url, err)
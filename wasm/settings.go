@@ -0,0 +1,43 @@
+package main
+
+import (
+	"strconv"
+
+	. "libble/shared"
+
+	dom "honnef.co/go/js/dom/v2"
+)
+
+func setupSettings(data *SaveData) {
+	doc := dom.GetWindow().Document()
+	cooldownInput, ok := doc.GetElementByID("cooldownInput").(*dom.HTMLInputElement)
+	if !ok {
+		return
+	}
+	note := doc.GetElementByID("cooldownNote")
+	noteText := ""
+	if note != nil {
+		noteText = note.TextContent()
+	}
+	setNote := func(text string) {
+		if note != nil {
+			note.SetTextContent(text)
+		}
+	}
+
+	cooldownInput.SetValue(strconv.FormatInt(data.Player.BookCooldown(), 10))
+	cooldownInput.AddEventListener("change", false, func(e dom.Event) {
+		days, err := strconv.Atoi(cooldownInput.Value())
+		if err == nil {
+			err = data.Player.SetBookCooldown(days)
+		}
+		if err != nil {
+			setNote(err.Error())
+			cooldownInput.SetValue(strconv.FormatInt(data.Player.BookCooldown(), 10))
+			return
+		}
+		setNote(noteText)
+		log(saveNonStaticData(*data), "Failed saving book cooldown")
+		syncPlayer(data.Player)
+	})
+}
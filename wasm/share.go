@@ -0,0 +1,71 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"net/url"
+	"strconv"
+	"syscall/js"
+
+	. "libble/shared"
+)
+
+// Copies to the clipboard, falling back to the share sheet when that isn't allowed
+func shareText(text string) (copied bool, err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			err = fmt.Errorf("Failed sharing: %v", r)
+		}
+	}()
+
+	navigator := js.Global().Get("navigator")
+	if clipboard := navigator.Get("clipboard"); clipboard.Truthy() {
+		_, err = await(clipboard.Call("writeText", text))
+		if err == nil {
+			return true, nil
+		}
+	}
+
+	if navigator.Get("share").Truthy() {
+		shareData := js.Global().Get("Object").New()
+		shareData.Set("text", text)
+		if _, shareErr := await(navigator.Call("share", shareData)); shareErr != nil {
+			return false, errors.Join(err, shareErr)
+		}
+		return false, nil
+	}
+	return false, errors.Join(err, fmt.Errorf("Sharing isn't supported by this browser"))
+}
+
+// Link to the server's share page so chats unfurl it into a result card
+func shareLink(player Player, game *Game) (string, error) {
+	link, err := url.Parse(apiOrigin)
+	if err != nil {
+		return "", err
+	}
+	link = link.JoinPath("share", strconv.FormatUint(uint64(player.ID), 10), game.Day)
+	if game.Mode != ClassicMode {
+		link.RawQuery = url.Values{"mode": {string(game.Mode)}}.Encode()
+	}
+	return link.String(), nil
+}
+
+func onShare(data *SaveData, game *Game, setFeedback func(msg string, status FeedbackStatus)) {
+	text := game.ShareText(*data) + "\n" + location().Origin()
+	if !game.Practice {
+		if link, err := shareLink(data.Player, game); err == nil {
+			text = game.ShareText(*data) + "\n" + link
+		} else {
+			log(err, "Failed making share link")
+		}
+	}
+	go func() {
+		copied, err := shareText(text)
+		if err != nil {
+			log(err, "Failed sharing results")
+			setFeedback("Couldn't share your results", WarnStatus)
+		} else if copied {
+			setFeedback("Copied your results to the clipboard!", SuccessStatus)
+		}
+	}()
+}
@@ -3,6 +3,7 @@ package main
 import (
 	"fmt"
 	"net/http"
+	"net/url"
 	"strconv"
 	"strings"
 
@@ -27,6 +28,8 @@ func initStart() {
 		errorMessage.Class().Remove("visible")
 	}
 
+	setupRedeem(showError, hideError)
+
 	form.AddEventListener("submit", false, func(e dom.Event) {
 		e.PreventDefault()
 		hideError()
@@ -54,7 +57,11 @@ func initStart() {
 			}()
 
 			var data libble.SaveData
-			if err := fetch("/user/"+userGrid, &data, http.MethodPost); err != nil {
+			path := "/user/" + userGrid
+			if timezone := browserTimezone(); timezone != "" {
+				path += "?timezone=" + url.QueryEscape(timezone)
+			}
+			if err := fetch(path, &data, http.MethodPost); err != nil {
 				log(err, "Unabled to create user data")
 				showError(err.Error())
 				return
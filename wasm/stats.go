@@ -0,0 +1,184 @@
+package main
+
+import (
+	"cmp"
+	"fmt"
+	"net/http"
+	"slices"
+	"strconv"
+	"syscall/js"
+
+	. "libble/shared"
+
+	dom "honnef.co/go/js/dom/v2"
+)
+
+// Set while there's a sync that couldn't reach the server
+const pendingSyncKey = "pendingSync"
+
+func putPlayer(player Player) error {
+	path := "/player/" + strconv.FormatUint(uint64(player.ID), 10)
+	var res map[string]any
+	return fetchWithToken(path, player.Token, player, &res, http.MethodPut)
+}
+
+// Uploads the player's games so the server can serve stats.
+// When offline it's queued until the connection comes back.
+func syncPlayer(player Player) {
+	go func() {
+		if isOnline() {
+			err := putPlayer(player)
+			if err == nil {
+				log(saveData(pendingSyncKey, ""), "Failed clearing pending sync")
+				return
+			}
+			log(err, "Failed syncing player, trying again when back online")
+		}
+		log(saveData(pendingSyncKey, "true"), "Failed queueing player sync")
+	}()
+}
+
+func isOnline() bool {
+	return js.Global().Get("navigator").Get("onLine").Truthy()
+}
+
+// Sends the latest saved player if a sync was missed, now and whenever the browser reconnects
+func setupSyncQueue() {
+	flush := func() {
+		if pending, _ := loadData(pendingSyncKey); pending == "" || !isOnline() {
+			return
+		}
+		var player Player
+		if err := loadJson(saveKey("player"), &player); err != nil {
+			log(err, "Failed loading player for queued sync")
+			return
+		}
+		if err := putPlayer(player); err != nil {
+			log(err, "Failed sending queued player sync")
+			return
+		}
+		log(saveData(pendingSyncKey, ""), "Failed clearing pending sync")
+	}
+
+	dom.GetWindow().AddEventListener("online", false, func(e dom.Event) {
+		go flush()
+	})
+	go flush()
+}
+
+func setupStats(data *SaveData, game *Game) (showStats func()) {
+	doc := dom.GetWindow().Document()
+	modal := doc.GetElementByID("statsModal")
+	content := doc.GetElementByID("statsContent")
+	if modal == nil || content == nil {
+		logErr("Failed to get stats modal elements")
+		return func() {}
+	}
+
+	showStats = func() {
+		renderStats(content, data.Stats(game.Mode))
+		modal.Underlying().Call("showModal")
+	}
+
+	if statsBtn := doc.GetElementByID("statsBtn"); statsBtn != nil {
+		statsBtn.AddEventListener("click", false, func(e dom.Event) {
+			e.PreventDefault()
+			showStats()
+		})
+	}
+	if closeBtn := doc.GetElementByID("closeStatsBtn"); closeBtn != nil {
+		closeBtn.AddEventListener("click", false, func(e dom.Event) {
+			e.PreventDefault()
+			modal.Underlying().Call("close")
+		})
+	}
+	return showStats
+}
+
+func renderStats(content dom.Element, stats Stats) {
+	doc := dom.GetWindow().Document()
+	content.SetInnerHTML("")
+
+	add := func(parent dom.Element, tag string, class string, text string) dom.Element {
+		elem := doc.CreateElement(tag)
+		if class != "" {
+			elem.Class().SetString(class)
+		}
+		elem.SetTextContent(text)
+		parent.AppendChild(elem)
+		return elem
+	}
+
+	add(content, "p", "stats-mode", stats.Mode.String())
+
+	summary := add(content, "div", "stats-summary", "")
+	for _, stat := range []struct {
+		label string
+		value string
+	}{
+		{"Played", strconv.Itoa(stats.Played)},
+		{"Win %", fmt.Sprintf("%.0f", stats.WinPercent)},
+		{"Current Streak", strconv.Itoa(stats.CurrentStreak)},
+		{"Max Streak", strconv.Itoa(stats.MaxStreak)},
+	} {
+		cell := add(summary, "div", "stat", "")
+		add(cell, "div", "stat-value", stat.value)
+		add(cell, "div", "stat-label", stat.label)
+	}
+
+	add(content, "h3", "", "Guess Distribution")
+	mostWins := max(slices.Max(stats.GuessDistribution[:]), 1)
+	for i, wins := range stats.GuessDistribution {
+		row := add(content, "div", "distribution-row", "")
+		add(row, "span", "distribution-label", strconv.Itoa(i+1))
+		bar := add(row, "span", "distribution-bar", strconv.Itoa(wins))
+		bar.(dom.HTMLElement).Style().SetProperty("width", fmt.Sprintf("%d%%", max(wins*100/mostWins, 8)), "")
+	}
+	add(content, "p", "stats-note", fmt.Sprintf("Average attempts: %.1f", stats.AverageAttempts))
+	if stats.ArchivePlayed > 0 {
+		add(content, "p", "stats-note",
+			fmt.Sprintf("Archive games won: %d/%d", stats.ArchiveWon, stats.ArchivePlayed))
+	}
+
+	type accuracyRow struct {
+		label    string
+		accuracy Accuracy
+	}
+	addAccuracyTable := func(title string, rows []accuracyRow) {
+		if len(rows) == 0 {
+			return
+		}
+		add(content, "h3", "", title)
+		list := add(content, "ul", "accuracy-list", "")
+		for _, row := range rows {
+			text := fmt.Sprintf("%s: %d/%d (%.0f%%)",
+				row.label, row.accuracy.Won, row.accuracy.Played, row.accuracy.Percent())
+			add(list, "li", "", text)
+		}
+	}
+
+	const maxAuthorRows = 5
+	authorRows := make([]accuracyRow, 0, len(stats.ByAuthor))
+	for author, accuracy := range stats.ByAuthor {
+		authorRows = append(authorRows, accuracyRow{author, accuracy})
+	}
+	slices.SortFunc(authorRows, func(a accuracyRow, b accuracyRow) int {
+		if c := cmp.Compare(b.accuracy.Played, a.accuracy.Played); c != 0 {
+			return c
+		}
+		return cmp.Compare(a.label, b.label)
+	})
+	addAccuracyTable("Top Authors", authorRows[:min(len(authorRows), maxAuthorRows)])
+
+	years := make([]int, 0, len(stats.ByYear))
+	for year := range stats.ByYear {
+		years = append(years, year)
+	}
+	slices.Sort(years)
+	slices.Reverse(years)
+	yearRows := make([]accuracyRow, 0, len(years))
+	for _, year := range years {
+		yearRows = append(yearRows, accuracyRow{strconv.Itoa(year), stats.ByYear[year]})
+	}
+	addAccuracyTable("By Year Read", yearRows)
+}
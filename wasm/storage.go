@@ -0,0 +1,272 @@
+package main
+
+import (
+	"bytes"
+	"compress/gzip"
+	"errors"
+	"fmt"
+	"io"
+	"reflect"
+	"sync"
+	"syscall/js"
+
+	. "libble/shared"
+)
+
+// Where save data is kept in the browser.
+// Calls block, so never make them straight from a js callback.
+type storage interface {
+	// nil when nothing is stored at key
+	get(key string) ([]byte, error)
+	// compress is only a request, stores that can't hold binary ignore it
+	set(key string, value []byte, compress bool) error
+	remove(key string) error
+}
+
+var (
+	store     storage
+	storeOnce sync.Once
+)
+
+// IndexedDB when the browser allows it, localStorage otherwise
+func openStorage() storage {
+	storeOnce.Do(func() {
+		db, err := openIndexedDB()
+		if err != nil {
+			log(err, "Failed opening IndexedDB, falling back to localStorage")
+			store = localStore{}
+			return
+		}
+		store = db
+		log(migrateLocalStorage(db), "Failed moving saves out of localStorage")
+	})
+	return store
+}
+
+// Every key the save data is split into
+func saveDataKeys() []string {
+	t := reflect.TypeFor[SaveData]()
+	keys := make([]string, 0, t.NumField())
+	for i := range t.NumField() {
+		if jsonName := t.Field(i).Tag.Get("json"); jsonName != "" {
+			keys = append(keys, saveKey(jsonName))
+		}
+	}
+	return keys
+}
+
+// Saves from before IndexedDB was used live in localStorage, move them over once
+func migrateLocalStorage(db storage) error {
+	var err error
+	for _, key := range saveDataKeys() {
+		value, loadErr := loadData(key)
+		if loadErr != nil || value == "" {
+			err = errors.Join(err, loadErr)
+			continue
+		}
+		if existing, getErr := db.get(key); getErr != nil || existing != nil {
+			err = errors.Join(err, getErr)
+			continue
+		}
+
+		jsonName := key[len(saveKey("")):]
+		if setErr := db.set(key, []byte(value), IsStaticSaveDataField(jsonName)); setErr != nil {
+			err = errors.Join(err, setErr)
+			continue
+		}
+		err = errors.Join(err, localStore{}.remove(key))
+		fmt.Printf("Moved %s to IndexedDB\n", key)
+	}
+	return err
+}
+
+// gzip magic number, so compressed values can be told apart when loading
+var gzipHeader = []byte{0x1f, 0x8b}
+
+func decompress(b []byte) ([]byte, error) {
+	if !bytes.HasPrefix(b, gzipHeader) {
+		return b, nil
+	}
+	decompresser, err := gzip.NewReader(bytes.NewReader(b))
+	if err != nil {
+		return nil, err
+	}
+	defer decompresser.Close()
+	return io.ReadAll(decompresser)
+}
+
+type localStore struct{}
+
+func (localStore) get(key string) ([]byte, error) {
+	value, err := loadData(key)
+	if value == "" {
+		return nil, err
+	}
+	return []byte(value), err
+}
+
+// localStorage only holds strings, so values are never compressed here
+func (localStore) set(key string, value []byte, compress bool) error {
+	return saveData(key, string(value))
+}
+
+func (localStore) remove(key string) (err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			err = fmt.Errorf("Failed to remove from local storage: %v\n", r)
+		}
+	}()
+	js.Global().Get("localStorage").Call("removeItem", key)
+	return nil
+}
+
+const (
+	idbName    = "libble"
+	idbVersion = 1
+	idbStore   = "saves"
+)
+
+type indexedDB struct {
+	db js.Value
+}
+
+func openIndexedDB() (db indexedDB, err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			err = fmt.Errorf("Failed opening IndexedDB: %v", r)
+		}
+	}()
+	factory := js.Global().Get("indexedDB")
+	if factory.IsUndefined() || factory.IsNull() {
+		return db, fmt.Errorf("IndexedDB isn't supported")
+	}
+
+	request := factory.Call("open", idbName, idbVersion)
+	onUpgrade := js.FuncOf(func(this js.Value, args []js.Value) any {
+		database := request.Get("result")
+		if !database.Get("objectStoreNames").Call("contains", idbStore).Bool() {
+			database.Call("createObjectStore", idbStore)
+		}
+		return nil
+	})
+	defer onUpgrade.Release()
+	request.Set("onupgradeneeded", onUpgrade)
+
+	result, err := awaitRequest(request)
+	if err != nil {
+		return db, fmt.Errorf("Failed opening IndexedDB: %v", err)
+	}
+	return indexedDB{db: result}, nil
+}
+
+// Blocks until an IDBRequest succeeds or fails
+func awaitRequest(request js.Value) (js.Value, error) {
+	results := make(chan js.Value, 1)
+	errs := make(chan error, 1)
+
+	onSuccess := js.FuncOf(func(this js.Value, args []js.Value) any {
+		results <- request.Get("result")
+		return nil
+	})
+	defer onSuccess.Release()
+	onError := js.FuncOf(func(this js.Value, args []js.Value) any {
+		reason := "unknown reason"
+		if requestErr := request.Get("error"); !requestErr.IsNull() && !requestErr.IsUndefined() {
+			reason = requestErr.Call("toString").String()
+		}
+		errs <- errors.New(reason)
+		return nil
+	})
+	defer onError.Release()
+
+	request.Set("onsuccess", onSuccess)
+	request.Set("onerror", onError)
+	select {
+	case result := <-results:
+		return result, nil
+	case err := <-errs:
+		return js.Undefined(), err
+	}
+}
+
+func (i indexedDB) objectStore(mode string) js.Value {
+	return i.db.Call("transaction", idbStore, mode).Call("objectStore", idbStore)
+}
+
+func (i indexedDB) get(key string) (value []byte, err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			err = fmt.Errorf("Failed reading %s from IndexedDB: %v", key, r)
+		}
+	}()
+	result, err := awaitRequest(i.objectStore("readonly").Call("get", key))
+	if err != nil {
+		return nil, fmt.Errorf("Failed reading %s from IndexedDB: %v", key, err)
+	}
+	if result.IsUndefined() || result.IsNull() {
+		return nil, nil
+	}
+
+	value = make([]byte, result.Get("byteLength").Int())
+	js.CopyBytesToGo(value, result)
+	return decompress(value)
+}
+
+func (i indexedDB) set(key string, value []byte, shouldCompress bool) (err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			err = fmt.Errorf("Failed writing %s to IndexedDB: %v", key, r)
+		}
+	}()
+	if shouldCompress {
+		if value, err = compress(value); err != nil {
+			return fmt.Errorf("Failed to compress %s: %v", key, err)
+		}
+	}
+
+	array := js.Global().Get("Uint8Array").New(len(value))
+	js.CopyBytesToJS(array, value)
+	if _, err := awaitRequest(i.objectStore("readwrite").Call("put", array, key)); err != nil {
+		return fmt.Errorf("Failed writing %s to IndexedDB: %v", key, err)
+	}
+	return nil
+}
+
+func (i indexedDB) remove(key string) (err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			err = fmt.Errorf("Failed removing %s from IndexedDB: %v", key, r)
+		}
+	}()
+	_, err = awaitRequest(i.objectStore("readwrite").Call("delete", key))
+	return err
+}
+
+type saveRequest struct {
+	key      string
+	value    []byte
+	compress bool
+	done     chan error
+}
+
+var (
+	saveQueue     chan saveRequest
+	saveQueueOnce sync.Once
+)
+
+// Writes happen one at a time in order, so an older save can't land after a newer one
+func queueSave(key string, value []byte, compress bool) chan error {
+	saveQueueOnce.Do(func() {
+		saveQueue = make(chan saveRequest, 16)
+		go func() {
+			for request := range saveQueue {
+				err := openStorage().set(request.key, request.value, request.compress)
+				log(err, "Failed saving "+request.key)
+				request.done <- err
+			}
+		}()
+	})
+	done := make(chan error, 1)
+	saveQueue <- saveRequest{key, value, compress, done}
+	return done
+}